@@ -19,6 +19,9 @@ type API interface {
 	// Db returns the PMS database.
 	Db() *db.Instance
 
+	// Error sends an error message to the user through the statusbar.
+	Error(string, ...interface{})
+
 	// Library returns the current MPD library, or nil if it has not been retrieved yet.
 	Library() *songlist.Library
 
@@ -136,6 +139,10 @@ func (api *baseAPI) Library() *songlist.Library {
 	return api.library()
 }
 
+func (api *baseAPI) Error(fmt string, a ...interface{}) {
+	api.eventMessage <- message.Errorf(fmt, a...)
+}
+
 func (api *baseAPI) ListChanged() {
 	api.eventList <- 0
 }
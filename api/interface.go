@@ -1,6 +1,7 @@
 package api
 
 import (
+	"github.com/ambientsound/pms/message"
 	"github.com/ambientsound/pms/songlist"
 )
 
@@ -23,6 +24,7 @@ type SonglistWidget interface {
 }
 
 type MultibarWidget interface {
+	Message() message.Message
 	Mode() int
 	SetMode(int) error
 }
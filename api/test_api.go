@@ -13,11 +13,15 @@ import (
 )
 
 type testAPI struct {
-	messages  chan message.Message
-	options   *options.Options
-	song      *song.Song
-	songlist  songlist.Songlist
-	clipboard songlist.Songlist
+	db           *db.Instance
+	messages     chan message.Message
+	mpdClient    *mpd.Client
+	options      *options.Options
+	playerStatus pms_mpd.PlayerStatus
+	queue        *songlist.Queue
+	song         *song.Song
+	songlist     songlist.Songlist
+	clipboard    songlist.Songlist
 }
 
 func createTestSong() *song.Song {
@@ -30,13 +34,35 @@ func createTestSong() *song.Song {
 }
 
 func NewTestAPI() API {
-	return &testAPI{
+	a := &testAPI{
 		clipboard: songlist.New(),
+		db:        db.New(),
 		messages:  make(chan message.Message, 1024),
 		options:   options.New(),
 		song:      createTestSong(),
 		songlist:  songlist.New(),
 	}
+	a.db.Panel().Add(a.songlist)
+	a.db.Panel().Activate(a.songlist)
+	return a
+}
+
+// NewTestAPIWithState returns a test API with the given player status and queue.
+func NewTestAPIWithState(playerStatus pms_mpd.PlayerStatus, queue *songlist.Queue) API {
+	a := NewTestAPI().(*testAPI)
+	a.playerStatus = playerStatus
+	a.queue = queue
+	return a
+}
+
+// NewTestAPIWithClient returns a test API with the given player status, where
+// the queue and commands communicate with MPD using the given client.
+func NewTestAPIWithClient(playerStatus pms_mpd.PlayerStatus, client *mpd.Client) API {
+	a := NewTestAPI().(*testAPI)
+	a.playerStatus = playerStatus
+	a.mpdClient = client
+	a.queue = songlist.NewQueue(a.MpdClient, nil)
+	return a
 }
 
 func (api *testAPI) Clipboard() songlist.Songlist {
@@ -44,11 +70,15 @@ func (api *testAPI) Clipboard() songlist.Songlist {
 }
 
 func (api *testAPI) Db() *db.Instance {
-	return nil // FIXME
+	return api.db
+}
+
+func (api *testAPI) Error(fmt string, a ...interface{}) {
+	api.send(message.Errorf(fmt, a...))
 }
 
 func (api *testAPI) Library() *songlist.Library {
-	return nil // FIXME
+	return api.db.Library()
 }
 
 func (api *testAPI) ListChanged() {
@@ -56,11 +86,20 @@ func (api *testAPI) ListChanged() {
 }
 
 func (api *testAPI) Message(fmt string, a ...interface{}) {
-	api.messages <- message.Format(fmt, a...)
+	api.send(message.Format(fmt, a...))
+}
+
+// send adds normal messages to the message log, as the main loop does, so
+// that tests can inspect them.
+func (api *testAPI) send(msg message.Message) {
+	if msg.Type == message.Normal {
+		api.db.Messages().Add(msg)
+	}
+	api.messages <- msg
 }
 
 func (api *testAPI) MpdClient() *mpd.Client {
-	return nil // FIXME
+	return api.mpdClient
 }
 
 func (api *testAPI) Multibar() MultibarWidget {
@@ -76,11 +115,11 @@ func (api *testAPI) Options() *options.Options {
 }
 
 func (api *testAPI) PlayerStatus() (p pms_mpd.PlayerStatus) {
-	return // FIXME
+	return api.playerStatus
 }
 
 func (api *testAPI) Queue() *songlist.Queue {
-	return nil // FIXME
+	return api.queue
 }
 
 func (api *testAPI) Quit() {
@@ -96,7 +135,7 @@ func (api *testAPI) Song() *song.Song {
 }
 
 func (api *testAPI) Songlist() songlist.Songlist {
-	return api.songlist
+	return api.db.Panel().Current()
 }
 
 func (api *testAPI) Songlists() []songlist.Songlist {
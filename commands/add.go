@@ -8,6 +8,7 @@ import (
 	"github.com/ambientsound/pms/input/lexer"
 	"github.com/ambientsound/pms/song"
 	"github.com/ambientsound/pms/songlist"
+	"github.com/ambientsound/pms/utils"
 )
 
 // Add adds songs to MPD's queue.
@@ -15,6 +16,9 @@ type Add struct {
 	newcommand
 	api      api.API
 	songlist songlist.Songlist
+	next     bool
+	play     bool
+	position int
 }
 
 // NewAdd returns Add.
@@ -22,12 +26,33 @@ func NewAdd(api api.API) Command {
 	return &Add{
 		api:      api,
 		songlist: songlist.New(),
+		position: -1,
 	}
 }
 
 // Parse implements Command.
 func (cmd *Add) Parse() error {
 
+	// Check for a sub-command that decides where to add the songs.
+	tok, lit := cmd.ScanIgnoreWhitespace()
+	cmd.setTabCompleteVerbs(lit)
+
+	switch {
+	case tok != lexer.TokenIdentifier:
+		cmd.Unscan()
+	case lit == "next":
+		cmd.next = true
+	case lit == "play":
+		cmd.play = true
+	case lit == "at":
+		cmd.setTabCompleteEmpty()
+		if err := cmd.parsePosition(); err != nil {
+			return err
+		}
+	default:
+		cmd.Unscan()
+	}
+
 	// Add all songs specified on the command line.
 Loop:
 	for {
@@ -35,6 +60,7 @@ Loop:
 		tok, lit := cmd.Scan()
 		switch tok {
 		case lexer.TokenWhitespace:
+			cmd.setTabCompleteEmpty()
 			str = ""
 			continue
 		case lexer.TokenEnd:
@@ -49,7 +75,11 @@ Loop:
 
 	// No songs specified on command line. Use songlist selection instead.
 	if cmd.songlist.Len() == 0 {
-		cmd.songlist = cmd.api.Songlist().Selection()
+		list := cmd.api.Songlist()
+		if err := requireSongs(list); err != nil {
+			return err
+		}
+		cmd.songlist = list.Selection()
 		if cmd.songlist.Len() == 0 {
 			return fmt.Errorf("No selection, cannot add without any parameters.")
 		}
@@ -60,14 +90,51 @@ Loop:
 
 // Exec implements Command.
 func (cmd *Add) Exec() error {
+	var err error
+
 	list := cmd.api.Songlist()
 	queue := cmd.api.Queue()
+	playerStatus := cmd.api.PlayerStatus()
+	position := song.NullPosition
+	id := song.NullID
+
+	switch {
+	case cmd.next:
+		if playerStatus.Song < 0 {
+			return fmt.Errorf("No song is playing, cannot add songs after it.")
+		}
+		position = playerStatus.Song + 1
+		err = queue.InsertListNext(cmd.songlist, position)
+	case cmd.position >= 0:
+		position = utils.Min(cmd.position, queue.Len())
+		err = queue.InsertList(cmd.songlist, position)
+	case cmd.play:
+		// The local copy of the queue might not be up to date, so the
+		// first song is played by the ID assigned to it by MPD.
+		id, err = queue.AddListID(cmd.songlist)
+	default:
+		err = queue.AddList(cmd.songlist)
+	}
 
-	err := queue.AddList(cmd.songlist)
 	if err != nil {
 		return err
 	}
 
+	if cmd.play {
+		client := cmd.api.MpdClient()
+		if client == nil {
+			return fmt.Errorf("Unable to play: cannot communicate with MPD")
+		}
+		if id != song.NullID {
+			err = client.PlayID(id)
+		} else {
+			err = client.Play(position)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
 	list.ClearSelection()
 	list.MoveCursor(1)
 	len := cmd.songlist.Len()
@@ -80,3 +147,25 @@ func (cmd *Add) Exec() error {
 
 	return nil
 }
+
+// parsePosition parses the queue position used by 'add at'.
+func (cmd *Add) parsePosition() error {
+	_, i, absolute, err := cmd.ParseInt()
+	if err != nil {
+		return err
+	}
+	if !absolute || i < 1 {
+		return fmt.Errorf("Queue position must be 1 or greater")
+	}
+	cmd.position = i - 1
+	return nil
+}
+
+// setTabCompleteVerbs sets the tab complete list to the list of available sub-commands.
+func (cmd *Add) setTabCompleteVerbs(lit string) {
+	cmd.setTabComplete(lit, []string{
+		"at",
+		"next",
+		"play",
+	})
+}
@@ -1,22 +1,46 @@
 package commands_test
 
 import (
+	"bufio"
+	"fmt"
+	"net"
 	"strings"
 	"testing"
 
+	"github.com/ambientsound/gompd/mpd"
+	"github.com/ambientsound/pms/api"
 	"github.com/ambientsound/pms/commands"
+	"github.com/ambientsound/pms/input/lexer"
+	pms_mpd "github.com/ambientsound/pms/mpd"
+	"github.com/ambientsound/pms/song"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 var addTests = []commands.Test{
 	// Valid forms
-	{``, true, initSongTags, nil, []string{}},
+	{``, true, initSongTags, nil, []string{"at", "next", "play"}},
 	{`foo bar baz`, true, nil, nil, []string{}},
 	{`http://example.com/stream.mp3?foo=bar&baz=foo foo bar baz`, true, nil, nil, []string{}},
 	{`|`, true, nil, nil, []string{}},
 	{`|{}$`, true, nil, nil, []string{}},
+	{`next`, true, initSongTags, nil, []string{"next"}},
+	{`next foo bar`, true, nil, nil, []string{}},
+	{`play`, true, initSongTags, nil, []string{"play"}},
+	{`play foo`, true, nil, nil, []string{}},
+	{`at 1`, true, initSongTags, nil, []string{}},
+	{`at 3 foo bar`, true, nil, nil, []string{}},
 
-	// No invalid forms, all input is accepted
+	// Invalid forms
+	{`next`, false, nil, nil, []string{"next"}},
+	{`at`, false, initSongTags, nil, []string{}},
+	{`at 0`, false, initSongTags, nil, []string{}},
+	{`at +1`, false, initSongTags, nil, []string{}},
+	{`at foo`, false, initSongTags, nil, []string{}},
+	{``, false, initListsView, nil, []string{"at", "next", "play"}},
+
+	// Tab completion
+	{`n`, true, initSongTags, nil, []string{"next"}},
 }
 
 func TestAdd(t *testing.T) {
@@ -32,3 +56,106 @@ func testMultipleSongsAdded(data *commands.TestData) {
 		assert.Equal(data.T, files[i], song.StringTags["file"], "Song %d should have URI '%s'", i, files[i])
 	}
 }
+
+// fakeMPD accepts a single connection from an MPD client, and records the
+// commands it receives, excluding command list delimiters. Songs added with
+// 'addid' are assigned IDs counting from 100.
+func fakeMPD(t *testing.T) (*mpd.Client, func() []string) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("unable to listen on loopback: %s", err)
+	}
+
+	received := make(chan []string, 1)
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			received <- nil
+			return
+		}
+		defer conn.Close()
+
+		lines := make([]string, 0)
+		list := make([]string, 0)
+		inList := false
+		id := 100
+		conn.Write([]byte("OK MPD 0.23.5\n"))
+		scanner := bufio.NewScanner(conn)
+		for scanner.Scan() {
+			line := scanner.Text()
+			switch {
+			case line == "command_list_ok_begin":
+				inList = true
+			case line == "command_list_end":
+				for _, cmd := range list {
+					if strings.HasPrefix(cmd, "addid ") {
+						fmt.Fprintf(conn, "Id: %d\n", id)
+						id++
+					}
+					conn.Write([]byte("list_OK\n"))
+				}
+				conn.Write([]byte("OK\n"))
+				lines = append(lines, list...)
+				list = list[:0]
+				inList = false
+			case inList:
+				list = append(list, line)
+			case line == "close":
+			default:
+				lines = append(lines, line)
+				conn.Write([]byte("OK\n"))
+			}
+		}
+		received <- lines
+	}()
+
+	client, err := mpd.Dial("tcp", listener.Addr().String())
+	if err != nil {
+		listener.Close()
+		t.Fatalf("unable to connect to fake MPD server: %s", err)
+	}
+
+	return client, func() []string {
+		client.Close()
+		listener.Close()
+		return <-received
+	}
+}
+
+// Test that songs are added to the queue at the right position, that
+// 'add play' starts playing the first added song, and that 'add next' fails
+// when no song is playing.
+func TestAddExec(t *testing.T) {
+	playing := pms_mpd.PlayerStatus{Song: 2, SongID: 12, State: pms_mpd.StatePlay}
+	stopped := pms_mpd.PlayerStatus{Song: song.NullPosition, SongID: song.NullID, State: pms_mpd.StateStop}
+
+	tests := []struct {
+		input    string
+		status   pms_mpd.PlayerStatus
+		success  bool
+		commands []string
+	}{
+		{`a b`, playing, true, []string{`add "a"`, `add "b"`}},
+		{`next a b`, playing, true, []string{`addid "a" 3`, `addid "b" 4`}},
+		{`next a b`, stopped, false, []string{}},
+		{`play a b`, playing, true, []string{`addid "a"`, `addid "b"`, `playid 100`}},
+		{`at 1 a b`, playing, true, []string{`addid "a" 0`, `addid "b" 1`}},
+	}
+
+	for i, test := range tests {
+		client, finish := fakeMPD(t)
+		a := api.NewTestAPIWithClient(test.status, client)
+		cmd := commands.New("add", a)
+		cmd.SetScanner(lexer.NewScanner(strings.NewReader(test.input)))
+
+		require.Nil(t, cmd.Parse(), "test %d: '%s'", i+1, test.input)
+		err := cmd.Exec()
+		if test.success {
+			assert.Nil(t, err, "test %d: '%s'", i+1, test.input)
+		} else {
+			assert.NotNil(t, err, "test %d: '%s'", i+1, test.input)
+		}
+		assert.Equal(t, test.commands, finish(), "test %d: '%s'", i+1, test.input)
+	}
+}
@@ -5,6 +5,8 @@ package commands
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 	"sort"
 	"strings"
 
@@ -12,43 +14,55 @@ import (
 	"github.com/ambientsound/pms/input/lexer"
 	"github.com/ambientsound/pms/parser"
 	"github.com/ambientsound/pms/song"
+	"github.com/ambientsound/pms/songlist"
 	"github.com/ambientsound/pms/utils"
 )
 
 // Verbs contain mappings from strings to Command constructors.
 // Make sure to add commands here when implementing them.
 var Verbs = map[string]func(api.API) Command{
-	"add":       NewAdd,
-	"bind":      NewBind,
-	"copy":      NewYank,
-	"cursor":    NewCursor,
-	"cut":       NewCut,
-	"inputmode": NewInputMode,
-	"isolate":   NewIsolate,
-	"list":      NewList,
-	"next":      NewNext,
-	"paste":     NewPaste,
-	"pause":     NewPause,
-	"play":      NewPlay,
-	"previous":  NewPrevious,
-	"prev":      NewPrevious,
-	"print":     NewPrint,
-	"q":         NewQuit,
-	"quit":      NewQuit,
-	"redraw":    NewRedraw,
-	"seek":      NewSeek,
-	"select":    NewSelect,
-	"se":        NewSet,
-	"set":       NewSet,
-	"single":    NewSingle,
-	"sort":      NewSort,
-	"stop":      NewStop,
-	"style":     NewStyle,
-	"unbind":    NewUnbind,
-	"update":    NewUpdate,
-	"viewport":  NewViewport,
-	"volume":    NewVolume,
-	"yank":      NewYank,
+	"add":        NewAdd,
+	"bind":       NewBind,
+	"copy":       NewYank,
+	"cursor":     NewCursor,
+	"cut":        NewCut,
+	"duplicates": NewDuplicates,
+	"export":     NewExport,
+	"import":     NewImport,
+	"inputmode":  NewInputMode,
+	"isolate":    NewIsolate,
+	"list":       NewList,
+	"lists":      NewLists,
+	"messages":   NewMessages,
+	"move":       NewMove,
+	"next":       NewNext,
+	"paste":      NewPaste,
+	"pause":      NewPause,
+	"play":       NewPlay,
+	"previous":   NewPrevious,
+	"prev":       NewPrevious,
+	"print":      NewPrint,
+	"prio":       NewPrio,
+	"q":          NewQuit,
+	"quit":       NewQuit,
+	"redraw":     NewRedraw,
+	"report":     NewReport,
+	"seek":       NewSeek,
+	"select":     NewSelect,
+	"se":         NewSet,
+	"set":        NewSet,
+	"shuffle":    NewShuffle,
+	"single":     NewSingle,
+	"sort":       NewSort,
+	"stats":      NewStats,
+	"stop":       NewStop,
+	"style":      NewStyle,
+	"tag":        NewTag,
+	"unbind":     NewUnbind,
+	"update":     NewUpdate,
+	"viewport":   NewViewport,
+	"volume":     NewVolume,
+	"yank":       NewYank,
 }
 
 // Command must be implemented by all commands.
@@ -160,6 +174,49 @@ func (c *newcommand) ParseTags(song *song.Song) ([]string, error) {
 	}
 }
 
+// ParseFilename parses a file name, which ends at the first unquoted
+// whitespace character. A leading tilde is expanded to the home directory.
+func (c *newcommand) ParseFilename() (string, error) {
+	c.setTabCompleteEmpty()
+	filename := ""
+
+	tok, lit := c.ScanIgnoreWhitespace()
+	for {
+		switch tok {
+		case lexer.TokenWhitespace, lexer.TokenEnd, lexer.TokenComment:
+			c.Unscan()
+			if len(filename) == 0 {
+				return "", fmt.Errorf("Unexpected '%s', expected file name", lit)
+			}
+			return expandHome(filename), nil
+		default:
+			filename += lit
+		}
+		tok, lit = c.Scan()
+	}
+}
+
+// expandHome replaces a leading tilde in a path with the home directory.
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[1:])
+}
+
+// requireSongs returns an error if the rows of a songlist are not songs, as
+// in the lists view, where each row refers to another songlist.
+func requireSongs(list songlist.Songlist) error {
+	if _, ok := list.(songlist.Opener); ok {
+		return fmt.Errorf("'%s' does not contain songs; use 'list open' to open an entry.", list.Name())
+	}
+	return nil
+}
+
 //
 // These functions belong to the old implementation.
 // FIXME: remove everything below.
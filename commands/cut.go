@@ -28,6 +28,10 @@ func (cmd *Cut) Parse() error {
 // Exec implements Command.
 func (cmd *Cut) Exec() error {
 	list := cmd.api.Songlist()
+	if err := requireSongs(list); err != nil {
+		return err
+	}
+
 	selection := list.Selection()
 	indices := list.SelectionIndices()
 	len := len(indices)
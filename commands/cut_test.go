@@ -11,6 +11,9 @@ var cutTests = []commands.Test{
 	{``, true, nil, nil, []string{}},
 	{`    `, true, nil, nil, []string{}},
 
+	// The lists view does not contain songs.
+	{``, true, initListsView, testRefusedInListsView, []string{}},
+
 	// Invalid forms
 	{`foo`, false, nil, nil, []string{}},
 	{`foo bar`, false, nil, nil, []string{}},
@@ -0,0 +1,55 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ambientsound/pms/api"
+	"github.com/ambientsound/pms/songlist"
+)
+
+// Duplicates finds songs in the library that have the same tag values.
+type Duplicates struct {
+	newcommand
+	api  api.API
+	tags []string
+}
+
+// NewDuplicates returns Duplicates.
+func NewDuplicates(api api.API) Command {
+	return &Duplicates{
+		api:  api,
+		tags: make([]string, 0),
+	}
+}
+
+// Parse implements Command.
+func (cmd *Duplicates) Parse() error {
+	var err error
+	list := cmd.api.Songlist()
+	cmd.tags, err = cmd.ParseTags(list.CursorSong())
+	return err
+}
+
+// Exec implements Command.
+func (cmd *Duplicates) Exec() error {
+	library := cmd.api.Library()
+	if library == nil {
+		return fmt.Errorf("Song library is not present.")
+	}
+
+	maxTimeDiff := cmd.api.Options().IntValue("duplicatetime")
+	result := songlist.Duplicates(library.Songs(), cmd.tags, maxTimeDiff)
+
+	if result.Len() == 0 {
+		return fmt.Errorf("No duplicates found when comparing %s", strings.Join(cmd.tags, ", "))
+	}
+
+	panel := cmd.api.Db().Panel()
+	panel.Add(result)
+	panel.Activate(result)
+
+	cmd.api.Message("Found %d tracks with duplicate %s", result.Len(), strings.Join(cmd.tags, ", "))
+
+	return nil
+}
@@ -0,0 +1,24 @@
+package commands_test
+
+import (
+	"testing"
+
+	"github.com/ambientsound/pms/commands"
+)
+
+var duplicatesTests = []commands.Test{
+	// Valid forms
+	{`artist`, true, initSongTags, nil, []string{"artist"}},
+	{`artist title`, true, initSongTags, nil, []string{"title"}},
+	{`musicbrainz_trackid`, true, initSongTags, nil, []string{}},
+
+	// Invalid forms
+	{``, false, initSongTags, nil, []string{}},
+
+	// Tab completion
+	{`ar`, true, initSongTags, nil, []string{"artist"}},
+}
+
+func TestDuplicates(t *testing.T) {
+	commands.TestVerb(t, "duplicates", duplicatesTests)
+}
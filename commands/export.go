@@ -0,0 +1,117 @@
+package commands
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/ambientsound/pms/api"
+	"github.com/ambientsound/pms/input/lexer"
+	"github.com/ambientsound/pms/playlist"
+	"github.com/ambientsound/pms/song"
+	"github.com/ambientsound/pms/songlist"
+)
+
+// Export writes the songlist, or the selected songs, to a playlist file.
+type Export struct {
+	newcommand
+	api      api.API
+	format   string
+	filename string
+}
+
+// NewExport returns Export.
+func NewExport(api api.API) Command {
+	return &Export{
+		api: api,
+	}
+}
+
+// Parse implements Command.
+func (cmd *Export) Parse() error {
+	var err error
+
+	tok, lit := cmd.ScanIgnoreWhitespace()
+	cmd.setTabComplete(lit, playlist.ExportFormats())
+	if tok != lexer.TokenIdentifier {
+		return fmt.Errorf("Unexpected '%s', expected export format", lit)
+	}
+	if !playlist.CanExport(lit) {
+		return fmt.Errorf("Unknown export format '%s'", lit)
+	}
+	cmd.format = lit
+
+	cmd.filename, err = cmd.ParseFilename()
+	if err != nil {
+		return err
+	}
+
+	return cmd.ParseEnd()
+}
+
+// Exec implements Command.
+func (cmd *Export) Exec() error {
+	list := cmd.api.Songlist()
+	if err := requireSongs(list); err != nil {
+		return err
+	}
+
+	songs := cmd.songs(list)
+	if len(songs) == 0 {
+		return fmt.Errorf("No tracks to export.")
+	}
+
+	specs, err := songlist.ParseColumnSpecs(songlist.ColumnOption(list, cmd.api.Options()))
+	if err != nil {
+		return err
+	}
+
+	// Write to a temporary file first, so that an existing playlist is not
+	// truncated if the export fails halfway.
+	file, err := ioutil.TempFile(filepath.Dir(cmd.filename), "."+filepath.Base(cmd.filename)+".")
+	if err != nil {
+		return err
+	}
+
+	err = playlist.Export(file, cmd.format, songs, songlist.Tags(specs))
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(file.Name(), exportMode(cmd.filename))
+	}
+	if err == nil {
+		err = os.Rename(file.Name(), cmd.filename)
+	}
+	if err != nil {
+		os.Remove(file.Name())
+		return err
+	}
+
+	cmd.api.Message("Exported %d tracks to '%s'", len(songs), cmd.filename)
+	list.ClearSelection()
+
+	return nil
+}
+
+// songs returns the selected songs, or all songs in the list if no songs are
+// selected.
+func (cmd *Export) songs(list songlist.Songlist) []*song.Song {
+	for i := 0; i < list.Len(); i++ {
+		if list.Selected(i) {
+			return list.Selection().Songs()
+		}
+	}
+	return list.Songs()
+}
+
+// exportMode returns the file mode of an existing playlist file, so that it is
+// kept when the file is overwritten. New playlist files are readable by all.
+func exportMode(filename string) os.FileMode {
+	info, err := os.Stat(filename)
+	if err != nil {
+		return 0644
+	}
+	return info.Mode().Perm()
+}
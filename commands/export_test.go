@@ -0,0 +1,106 @@
+package commands_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ambientsound/gompd/mpd"
+	"github.com/ambientsound/pms/commands"
+	"github.com/ambientsound/pms/options"
+	"github.com/ambientsound/pms/song"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExport(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pms-export")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "songs.csv")
+
+	exportTests := []commands.Test{
+		// Valid forms
+		{`csv ` + filename, true, initExport, testExported(filename, "artist,title\nfoo,bar\nbaz,qux\n"), []string{}},
+		{`csv "` + filename + `"  `, true, initExport, nil, []string{}},
+		{`m3u ` + filename, true, nil, nil, []string{}},
+
+		// Existing files are replaced, keeping their permissions
+		{`csv ` + filename, true, initExport, testExportReplaces(filename), []string{}},
+
+		// Only the selection is exported
+		{`csv ` + filename, true, initExportSelection, testExported(filename, "artist,title\nbaz,qux\n"), []string{}},
+
+		// Empty lists cannot be exported
+		{`csv ` + filename, true, nil, testExportFails, []string{}},
+
+		// The lists view does not contain songs
+		{`csv ` + filename, true, initListsView, testRefusedInListsView, []string{}},
+
+		// Invalid forms
+		{``, false, nil, nil, []string{"csv", "json", "m3u", "m3u8", "xspf"}},
+		{`csv`, false, nil, nil, []string{}},
+		{`pls ` + filename, false, nil, nil, []string{}},
+		{`csv foo bar`, false, nil, nil, []string{}},
+
+		// Tab completion
+		{`m3`, false, nil, nil, []string{"m3u", "m3u8"}},
+	}
+
+	commands.TestVerb(t, "export", exportTests)
+}
+
+func initExport(data *commands.TestData) {
+	columns := options.NewStringOption("columns")
+	columns.Set("artist,title")
+	data.Api.Options().Add(columns)
+	for _, tags := range []mpd.Attrs{
+		{"artist": "foo", "title": "bar"},
+		{"artist": "baz", "title": "qux"},
+	} {
+		s := song.New()
+		s.SetTags(tags)
+		data.Api.Songlist().Add(s)
+	}
+}
+
+func initExportSelection(data *commands.TestData) {
+	initExport(data)
+	data.Api.Songlist().SetSelected(1, true)
+}
+
+func testExported(filename, expected string) func(data *commands.TestData) {
+	return func(data *commands.TestData) {
+		err := data.Cmd.Exec()
+		assert.Nil(data.T, err)
+		content, err := ioutil.ReadFile(filename)
+		assert.Nil(data.T, err)
+		assert.Equal(data.T, expected, string(content))
+	}
+}
+
+func testExportReplaces(filename string) func(data *commands.TestData) {
+	return func(data *commands.TestData) {
+		assert.Nil(data.T, ioutil.WriteFile(filename, []byte("old content"), 0600))
+		assert.Nil(data.T, os.Chmod(filename, 0600))
+		testExported(filename, "artist,title\nfoo,bar\nbaz,qux\n")(data)
+
+		info, err := os.Stat(filename)
+		if assert.Nil(data.T, err) {
+			assert.Equal(data.T, os.FileMode(0600), info.Mode().Perm())
+		}
+
+		// No temporary files are left behind.
+		files, err := ioutil.ReadDir(filepath.Dir(filename))
+		assert.Nil(data.T, err)
+		assert.Len(data.T, files, 1)
+	}
+}
+
+func testExportFails(data *commands.TestData) {
+	err := data.Cmd.Exec()
+	assert.NotNil(data.T, err)
+}
@@ -0,0 +1,104 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ambientsound/pms/api"
+	"github.com/ambientsound/pms/playlist"
+	"github.com/ambientsound/pms/songlist"
+)
+
+// Import reads a playlist file, and opens the songs in it as a new songlist.
+type Import struct {
+	newcommand
+	api      api.API
+	filename string
+}
+
+// NewImport returns Import.
+func NewImport(api api.API) Command {
+	return &Import{
+		api: api,
+	}
+}
+
+// Parse implements Command.
+func (cmd *Import) Parse() error {
+	var err error
+
+	cmd.filename, err = cmd.ParseFilename()
+	if err != nil {
+		return err
+	}
+
+	format := playlist.FormatFromPath(cmd.filename)
+	if !playlist.CanImport(format) {
+		return fmt.Errorf("Unknown playlist format '%s'", format)
+	}
+
+	return cmd.ParseEnd()
+}
+
+// Exec implements Command.
+func (cmd *Import) Exec() error {
+	library := cmd.api.Library()
+	if library == nil {
+		return fmt.Errorf("Song library is not present.")
+	}
+
+	file, err := os.Open(cmd.filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	entries, err := playlist.Import(file, playlist.FormatFromPath(cmd.filename))
+	if err != nil {
+		return err
+	}
+
+	musicDir := expandHome(cmd.api.Options().StringValue("musicdir"))
+	playlistDir, err := filepath.Abs(filepath.Dir(cmd.filename))
+	if err != nil {
+		return err
+	}
+	files := library.Files()
+	list := songlist.New()
+	list.SetName(filepath.Base(cmd.filename))
+	unresolved := make([]string, 0)
+
+	for _, entry := range entries {
+		uri, ok := entry.URI(musicDir, playlistDir)
+		song := files[uri]
+		if (!ok || song == nil) && library.HasIndex() {
+			song, err = library.Match(entry.Tags(), entry.Time)
+			if err != nil {
+				return fmt.Errorf("While looking up '%s' in the search index: %s", entry.Location, err)
+			}
+		}
+		if song == nil {
+			unresolved = append(unresolved, entry.Location)
+			continue
+		}
+		list.Add(song)
+	}
+
+	if list.Len() == 0 {
+		return fmt.Errorf("None of the %d playlist entries were found in the song library.", len(entries))
+	}
+
+	panel := cmd.api.Db().Panel()
+	panel.Add(list)
+	panel.Activate(list)
+
+	if len(unresolved) > 0 {
+		cmd.api.Message("Imported %d tracks from '%s'; %d entries could not be resolved: %s", list.Len(), cmd.filename, len(unresolved), strings.Join(unresolved, ", "))
+	} else {
+		cmd.api.Message("Imported %d tracks from '%s'", list.Len(), cmd.filename)
+	}
+
+	return nil
+}
@@ -0,0 +1,130 @@
+package commands_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ambientsound/gompd/mpd"
+	"github.com/ambientsound/pms/commands"
+	"github.com/ambientsound/pms/options"
+	"github.com/ambientsound/pms/song"
+	"github.com/ambientsound/pms/songlist"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var importTests = []commands.Test{
+	// Valid forms
+	{`foo.m3u`, true, nil, testRequiresLibrary, []string{}},
+	{`"/tmp/foo bar.M3U8"`, true, nil, nil, []string{}},
+	{`~/foo.pls`, true, nil, nil, []string{}},
+	{`foo-bar.xspf  `, true, nil, nil, []string{}},
+
+	// Invalid forms
+	{``, false, nil, nil, []string{}},
+	{`foo.csv`, false, nil, nil, []string{}},
+	{`foo`, false, nil, nil, []string{}},
+	{`foo.m3u bar`, false, nil, nil, []string{}},
+}
+
+func TestImport(t *testing.T) {
+	commands.TestVerb(t, "import", importTests)
+}
+
+// Test that playlist entries are resolved against the song library, relative
+// to both the playlist directory and the music directory.
+func TestImportExec(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pms-import")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	musicDir := filepath.Join(dir, "music")
+	playlistDir := filepath.Join(dir, "playlists")
+	require.Nil(t, os.MkdirAll(playlistDir, 0755))
+
+	resolvable := filepath.Join(playlistDir, "resolvable.m3u")
+	content := strings.Join([]string{
+		"../music/foo/a.flac",
+		"missing.flac",
+		filepath.Join(musicDir, "foo", "b.flac"),
+		"other.flac",
+	}, "\n")
+	require.Nil(t, ioutil.WriteFile(resolvable, []byte(content), 0644))
+
+	unresolvable := filepath.Join(playlistDir, "unresolvable.m3u")
+	require.Nil(t, ioutil.WriteFile(unresolvable, []byte("missing.flac\n"), 0644))
+
+	initImport := func(data *commands.TestData) {
+		initLibrary(data)
+		option := options.NewStringOption("musicdir")
+		option.Set(musicDir)
+		data.Api.Options().Add(option)
+	}
+
+	importTests := []commands.Test{
+		{resolvable, true, initImport, testImported([]string{"foo/a.flac", "foo/b.flac"}, []string{
+			"Imported 2 tracks from '" + resolvable + "'; 2 entries could not be resolved: missing.flac, other.flac",
+		}), []string{}},
+		{unresolvable, true, initImport, testImportFails, []string{}},
+	}
+
+	commands.TestVerb(t, "import", importTests)
+}
+
+// initLibrary sets up a song library with three songs.
+func initLibrary(data *commands.TestData) {
+	library := songlist.NewLibrary()
+	for _, tags := range []mpd.Attrs{
+		{"file": "foo/a.flac", "artist": "foo", "time": "100"},
+		{"file": "foo/b.flac", "artist": "foo", "time": "200"},
+		{"file": "bar/c.flac", "artist": "bar", "time": "250"},
+	} {
+		s := song.New()
+		s.SetTags(tags)
+		library.Add(s)
+	}
+	data.Api.Db().SetLibrary(library)
+}
+
+// testRequiresLibrary checks that the command fails without a song library.
+func testRequiresLibrary(data *commands.TestData) {
+	err := data.Cmd.Exec()
+	if assert.NotNil(data.T, err) {
+		assert.Contains(data.T, err.Error(), "library is not present")
+	}
+}
+
+func testImported(files []string, messages []string) func(data *commands.TestData) {
+	return func(data *commands.TestData) {
+		err := data.Cmd.Exec()
+		assert.Nil(data.T, err)
+
+		list := data.Api.Songlist()
+		assert.Equal(data.T, filepath.Base(data.Test.Input), list.Name())
+		assert.Equal(data.T, files, songFiles(list))
+
+		logged := make([]string, 0)
+		for _, msg := range data.Api.Db().Messages().Messages() {
+			logged = append(logged, msg.Text)
+		}
+		assert.Equal(data.T, messages, logged)
+	}
+}
+
+func testImportFails(data *commands.TestData) {
+	err := data.Cmd.Exec()
+	assert.NotNil(data.T, err)
+	assert.Equal(data.T, 1, data.Api.Db().Panel().Len())
+}
+
+// songFiles returns the file names of the songs in a songlist.
+func songFiles(list songlist.Songlist) []string {
+	files := make([]string, list.Len())
+	for i, s := range list.Songs() {
+		files[i] = s.StringTags["file"]
+	}
+	return files
+}
@@ -3,6 +3,7 @@ package commands
 import (
 	"fmt"
 	"strconv"
+	"strings"
 
 	"github.com/ambientsound/pms/api"
 	"github.com/ambientsound/pms/console"
@@ -12,14 +13,17 @@ import (
 
 // List navigates and manipulates songlists.
 type List struct {
-	command
+	newcommand
 	api       api.API
 	relative  int
 	absolute  int
 	duplicate bool
 	remove    bool
+	open      bool
+	name      string
 }
 
+// NewList returns List.
 func NewList(api api.API) Command {
 	return &List{
 		api:      api,
@@ -27,121 +31,231 @@ func NewList(api api.API) Command {
 	}
 }
 
-func (cmd *List) Execute(class int, s string) error {
+// Parse implements Command.
+func (cmd *List) Parse() error {
+	tok, lit := cmd.ScanIgnoreWhitespace()
+	cmd.setTabCompleteVerbs(lit)
+
+	switch tok {
+	case lexer.TokenIdentifier:
+	case lexer.TokenEnd:
+		return fmt.Errorf("Unexpected END, expected position. Try one of: next prev <number>")
+	default:
+		return fmt.Errorf("Unexpected '%s', expected position", lit)
+	}
+
+	switch lit {
+	case "duplicate":
+		cmd.duplicate = true
+	case "remove":
+		cmd.remove = true
+	case "open":
+		cmd.open = true
+	case "rename":
+		return cmd.parseRename()
+	case "up", "prev", "previous":
+		cmd.relative = -1
+	case "down", "next":
+		cmd.relative = 1
+	case "home":
+		cmd.absolute = 0
+	case "end":
+		cmd.absolute = cmd.api.Db().Panel().Len() - 1
+	default:
+		i, err := strconv.Atoi(lit)
+		if err != nil {
+			return fmt.Errorf("Cannot navigate lists: position '%s' is not recognized, and is not a number", lit)
+		}
+		cmd.absolute = i - 1
+	}
+
+	cmd.setTabCompleteEmpty()
+
+	return cmd.ParseEnd()
+}
+
+// parseRename parses the new name of the list, which spans the rest of the line.
+func (cmd *List) parseRename() error {
+	cmd.setTabCompleteEmpty()
+	name := ""
+
+	for {
+		tok, lit := cmd.Scan()
+		switch tok {
+		case lexer.TokenEnd, lexer.TokenComment:
+			cmd.name = strings.TrimSpace(name)
+			if len(cmd.name) == 0 {
+				return fmt.Errorf("Unexpected END, expected list name")
+			}
+			return nil
+		default:
+			name += lit
+		}
+	}
+}
+
+// Exec implements Command.
+func (cmd *List) Exec() error {
 	var err error
 	var index int
 
 	ui := cmd.api.UI()
 	collection := cmd.api.Db().Panel()
+	lists, inListsView := collection.Current().(*songlist.Lists)
 
-	switch class {
+	switch {
+	case len(cmd.name) > 0:
+		return cmd.rename()
 
-	case lexer.TokenIdentifier:
-		switch s {
-		case "duplicate":
-			cmd.duplicate = true
-		case "remove":
-			cmd.remove = true
-		case "up", "prev", "previous":
-			cmd.relative = -1
-		case "down", "next":
-			cmd.relative = 1
-		case "home":
-			cmd.absolute = 0
-		case "end":
-			cmd.absolute = collection.Len() - 1
-		default:
-			i, err := strconv.Atoi(s)
-			if err != nil {
-				return fmt.Errorf("Cannot navigate lists: position '%s' is not recognized, and is not a number", s)
-			}
-			switch {
-			case cmd.relative != 0 || cmd.absolute != -1:
-				return fmt.Errorf("Only one number allowed when setting list position")
-			case cmd.relative != 0:
-				cmd.relative *= i
-			default:
-				cmd.absolute = i - 1
-			}
-		}
+	case cmd.open:
+		return openCursor(cmd.api)
 
-	case lexer.TokenEnd:
-		switch {
-		case cmd.duplicate:
-			console.Log("Duplicating current songlist.")
-			orig := collection.Current()
-			list := songlist.New()
-			err = orig.Duplicate(list)
-			if err != nil {
-				return fmt.Errorf("Error during songlist duplication: %s", err)
-			}
-			name := fmt.Sprintf("%s (copy)", orig.Name())
-			list.SetName(name)
-			collection.Add(list)
-			index = collection.Len() - 1
+	case cmd.remove && inListsView:
+		lists.CommitVisualSelection()
+		lists.DisableVisualSelection()
+		err = lists.RemoveIndices(lists.SelectionIndices())
+		lists.ClearSelection()
+		cmd.api.ListChanged()
+		return err
 
-		case cmd.remove:
-			list := collection.Current()
-			console.Log("Removing current songlist '%s'.", list.Name())
-
-			err = list.Delete()
-			if err != nil {
-				return fmt.Errorf("Cannot remove songlist: %s", err)
-			}
+	case cmd.duplicate:
+		console.Log("Duplicating current songlist.")
+		orig := collection.Current()
+		if err = requireSongs(orig); err != nil {
+			return err
+		}
+		list := songlist.New()
+		err = orig.Duplicate(list)
+		if err != nil {
+			return fmt.Errorf("Error during songlist duplication: %s", err)
+		}
+		name := fmt.Sprintf("%s (copy)", orig.Name())
+		list.SetName(name)
+		collection.Add(list)
+		index = collection.Len() - 1
 
-			index, err = collection.Index()
-
-			// If we got an error here, it means that the current songlist is
-			// not in the list of songlists. In this case, we can reset to the
-			// last used songlist.
-			if err != nil {
-				fallback := collection.Last()
-				if fallback == nil {
-					return fmt.Errorf("No songlists left.")
-				}
-				console.Log("Songlist was not found in the list of songlists. Activating fallback songlist '%s'.", fallback.Name())
-				ui.PostFunc(func() {
-					collection.Activate(fallback)
-				})
-				return nil
-			} else {
-				collection.Remove(index)
-			}
+	case cmd.remove:
+		list := collection.Current()
+		console.Log("Removing current songlist '%s'.", list.Name())
 
-			// If removing the last songlist, we need to decrease the songlist index by one.
-			if index == collection.Len() {
-				index--
-			}
+		err = list.Delete()
+		if err != nil {
+			return fmt.Errorf("Cannot remove songlist: %s", err)
+		}
 
-			console.Log("Removed songlist, now activating songlist no. %d", index)
+		index, err = collection.Index()
 
-		case cmd.relative != 0:
-			index, err = collection.Index()
-			if err != nil {
-				index = 0
+		// If we got an error here, it means that the current songlist is
+		// not in the list of songlists. In this case, we can reset to the
+		// last used songlist.
+		if err != nil {
+			fallback := collection.Last()
+			if fallback == nil {
+				return fmt.Errorf("No songlists left.")
 			}
-			index += cmd.relative
-			if !collection.ValidIndex(index) {
-				len := collection.Len()
-				index = (index + len) % len
-			}
-			console.Log("Switching songlist index to relative %d, equalling absolute %d", cmd.relative, index)
+			console.Log("Songlist was not found in the list of songlists. Activating fallback songlist '%s'.", fallback.Name())
+			ui.PostFunc(func() {
+				collection.Activate(fallback)
+			})
+			return nil
+		} else {
+			collection.Remove(index)
+		}
 
-		case cmd.absolute >= 0:
-			console.Log("Switching songlist index to absolute %d", cmd.absolute)
-			index = cmd.absolute
+		// If removing the last songlist, we need to decrease the songlist index by one.
+		if index == collection.Len() {
+			index--
+		}
 
-		default:
-			return fmt.Errorf("Unexpected END, expected position. Try one of: next prev <number>")
+		console.Log("Removed songlist, now activating songlist no. %d", index)
+
+	case cmd.relative != 0:
+		index, err = collection.Index()
+		if err != nil {
+			index = 0
+		}
+		index += cmd.relative
+		if !collection.ValidIndex(index) {
+			len := collection.Len()
+			index = (index + len) % len
 		}
+		console.Log("Switching songlist index to relative %d, equalling absolute %d", cmd.relative, index)
 
-		ui.PostFunc(func() {
-			err = collection.ActivateIndex(index)
-		})
+	case cmd.absolute >= 0:
+		console.Log("Switching songlist index to absolute %d", cmd.absolute)
+		index = cmd.absolute
 
 	default:
-		return fmt.Errorf("Unknown input '%s', expected END", s)
+		return fmt.Errorf("Unexpected END, expected position. Try one of: next prev <number>")
 	}
 
+	ui.PostFunc(func() {
+		err = collection.ActivateIndex(index)
+	})
+
 	return err
 }
+
+// openCursor switches to the list referred to by the entry under the cursor,
+// in views such as the lists view and the statistics view. Lists that are not
+// already in the collection are added to it.
+func openCursor(api api.API) error {
+	current := api.Db().Panel().Current()
+	opener, ok := current.(songlist.Opener)
+	if !ok {
+		return fmt.Errorf("Lists can only be opened from the lists or statistics view.")
+	}
+
+	list, err := opener.Open(current.Cursor())
+	if err != nil {
+		return err
+	}
+
+	api.UI().PostFunc(func() {
+		collection := api.Db().Panel()
+		if !collection.Contains(list) {
+			collection.Add(list)
+		}
+		collection.Activate(list)
+	})
+
+	return nil
+}
+
+// rename changes the name of the current list. In the lists view, the list
+// under the cursor is renamed instead.
+func (cmd *List) rename() error {
+	list := cmd.api.Songlist()
+	lists, inListsView := list.(*songlist.Lists)
+	if inListsView {
+		list = lists.Songlist(lists.Cursor())
+		if list == nil {
+			return fmt.Errorf("No list selected.")
+		}
+	}
+
+	if err := list.SetName(cmd.name); err != nil {
+		return err
+	}
+
+	if inListsView {
+		lists.Refresh()
+	}
+	cmd.api.ListChanged()
+
+	return nil
+}
+
+// setTabCompleteVerbs sets the tab complete list to the list of available sub-commands.
+func (cmd *List) setTabCompleteVerbs(lit string) {
+	cmd.setTabComplete(lit, []string{
+		"duplicate",
+		"end",
+		"home",
+		"next",
+		"open",
+		"prev",
+		"remove",
+		"rename",
+	})
+}
@@ -0,0 +1,49 @@
+package commands_test
+
+import (
+	"testing"
+
+	"github.com/ambientsound/pms/commands"
+	"github.com/stretchr/testify/assert"
+)
+
+var listTests = []commands.Test{
+	// Valid forms
+	{`next`, true, nil, nil, []string{}},
+	{`prev`, true, nil, nil, []string{}},
+	{`home`, true, nil, nil, []string{}},
+	{`3`, true, nil, nil, []string{}},
+	{`duplicate`, true, nil, nil, []string{}},
+	{`duplicate`, true, initListsView, testRefusedInListsView, []string{}},
+	{`remove`, true, nil, nil, []string{}},
+	{`open`, true, nil, testListOpenOutsideListsView, []string{}},
+	{`rename foo`, true, nil, testListRenamed("foo"), []string{}},
+	{`rename  my "new list"  `, true, nil, testListRenamed("my new list"), []string{}},
+
+	// Invalid forms
+	{``, false, nil, nil, []string{"duplicate", "end", "home", "next", "open", "prev", "remove", "rename"}},
+	{`foo`, false, nil, nil, []string{}},
+	{`next 2`, false, nil, nil, []string{}},
+	{`rename`, false, nil, nil, []string{}},
+	{`rename   `, false, nil, nil, []string{}},
+
+	// Tab completion
+	{`re`, false, nil, nil, []string{"remove", "rename"}},
+}
+
+func TestList(t *testing.T) {
+	commands.TestVerb(t, "list", listTests)
+}
+
+func testListRenamed(name string) func(data *commands.TestData) {
+	return func(data *commands.TestData) {
+		err := data.Cmd.Exec()
+		assert.Nil(data.T, err)
+		assert.Equal(data.T, name, data.Api.Songlist().Name())
+	}
+}
+
+func testListOpenOutsideListsView(data *commands.TestData) {
+	err := data.Cmd.Exec()
+	assert.NotNil(data.T, err)
+}
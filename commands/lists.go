@@ -0,0 +1,47 @@
+package commands
+
+import (
+	"github.com/ambientsound/pms/api"
+	"github.com/ambientsound/pms/songlist"
+)
+
+// Lists opens a view of all songlists.
+type Lists struct {
+	newcommand
+	api api.API
+}
+
+// NewLists returns Lists.
+func NewLists(api api.API) Command {
+	return &Lists{
+		api: api,
+	}
+}
+
+// Parse implements Command.
+func (cmd *Lists) Parse() error {
+	return cmd.ParseEnd()
+}
+
+// Exec implements Command.
+func (cmd *Lists) Exec() error {
+	panel := cmd.api.Db().Panel()
+	current := panel.Current()
+	if lists, ok := current.(*songlist.Lists); ok {
+		lists.Refresh()
+		return nil
+	}
+
+	lists := songlist.NewLists(panel)
+	panel.Activate(lists)
+
+	// Place the cursor on the list that was visible.
+	for i := 0; i < lists.Len(); i++ {
+		if lists.Songlist(i) == current {
+			lists.SetCursor(i)
+			break
+		}
+	}
+
+	return nil
+}
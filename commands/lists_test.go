@@ -0,0 +1,45 @@
+package commands_test
+
+import (
+	"testing"
+
+	"github.com/ambientsound/pms/commands"
+	"github.com/ambientsound/pms/songlist"
+	"github.com/stretchr/testify/assert"
+)
+
+var listsTests = []commands.Test{
+	// Valid forms
+	{``, true, nil, testListsOpened, []string{}},
+
+	// Invalid forms
+	{`foo`, false, nil, nil, []string{}},
+}
+
+func TestLists(t *testing.T) {
+	commands.TestVerb(t, "lists", listsTests)
+}
+
+func initListsView(data *commands.TestData) {
+	panel := data.Api.Db().Panel()
+	panel.Activate(songlist.NewLists(panel))
+}
+
+func testRefusedInListsView(data *commands.TestData) {
+	err := data.Cmd.Exec()
+	assert.NotNil(data.T, err)
+	assert.Equal(data.T, 1, data.Api.Db().Panel().Len())
+	assert.Equal(data.T, 0, data.Api.Db().Clipboard("default").Len())
+}
+
+func testListsOpened(data *commands.TestData) {
+	err := data.Cmd.Exec()
+	assert.Nil(data.T, err)
+
+	lists, ok := data.Api.Db().Panel().Current().(*songlist.Lists)
+	if assert.True(data.T, ok) {
+		assert.Equal(data.T, 1, lists.Len())
+		assert.Equal(data.T, data.Api.Songlist(), lists)
+		assert.Equal(data.T, 0, lists.Cursor())
+	}
+}
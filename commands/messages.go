@@ -0,0 +1,61 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/ambientsound/pms/api"
+	"github.com/ambientsound/pms/input/lexer"
+	"github.com/ambientsound/pms/message"
+	"github.com/ambientsound/pms/songlist"
+)
+
+// Messages opens the message log as a songlist.
+type Messages struct {
+	newcommand
+	api        api.API
+	severities map[int]bool
+}
+
+// NewMessages returns Messages.
+func NewMessages(api api.API) Command {
+	return &Messages{
+		api:        api,
+		severities: make(map[int]bool),
+	}
+}
+
+// Parse implements Command.
+func (cmd *Messages) Parse() error {
+	for {
+		tok, lit := cmd.ScanIgnoreWhitespace()
+		cmd.setTabComplete(lit, message.SeverityNames())
+
+		switch tok {
+		case lexer.TokenIdentifier:
+			break
+		case lexer.TokenEnd, lexer.TokenComment:
+			cmd.setTabCompleteEmpty()
+			return nil
+		default:
+			return fmt.Errorf("Unexpected '%v', expected message severity", lit)
+		}
+
+		severity, err := message.ParseSeverity(lit)
+		if err != nil {
+			return err
+		}
+		cmd.severities[severity] = true
+	}
+}
+
+// Exec implements Command.
+func (cmd *Messages) Exec() error {
+	// Any message log already in the panel is replaced, so that it is kept
+	// in the same place.
+	log := songlist.NewLog(cmd.api.Db().Messages(), cmd.severities)
+	panel := cmd.api.Db().Panel()
+	panel.Replace(log)
+	panel.Activate(log)
+
+	return nil
+}
@@ -0,0 +1,79 @@
+package commands_test
+
+import (
+	"testing"
+
+	"github.com/ambientsound/pms/commands"
+	"github.com/ambientsound/pms/songlist"
+	"github.com/stretchr/testify/assert"
+)
+
+var messagesTests = []commands.Test{
+	// Valid forms
+	{``, true, nil, nil, []string{}},
+	{`error`, true, nil, nil, []string{}},
+	{`info error`, true, nil, nil, []string{}},
+
+	// Invalid forms
+	{`foo`, false, nil, nil, []string{}},
+	{`info foo`, false, nil, nil, []string{}},
+	{`1`, false, nil, nil, []string{}},
+
+	// Execution
+	{`error`, true, initMessages, testMessagesOpened, []string{}},
+
+	// Tab completion
+	{`e`, false, nil, nil, []string{
+		"error",
+	}},
+	{`info d`, false, nil, nil, []string{
+		"debug",
+	}},
+}
+
+func TestMessages(t *testing.T) {
+	commands.TestVerb(t, "messages", messagesTests)
+}
+
+func initMessages(data *commands.TestData) {
+	data.Api.Message("foo")
+	data.Api.Error("bar")
+}
+
+// testMessagesOpened checks that the message log shows messages of the given
+// severities, that it is reused when opened again, and that it is refreshed
+// along with the lists views.
+func testMessagesOpened(data *commands.TestData) {
+	panel := data.Api.Db().Panel()
+	lists := panel.Len()
+
+	err := data.Cmd.Exec()
+	assert.Nil(data.T, err)
+	log, ok := data.Api.Songlist().(*songlist.Log)
+	if assert.True(data.T, ok) {
+		assert.Equal(data.T, 1, log.Len())
+		assert.Equal(data.T, "bar", log.Song(0).StringTags["message"])
+	}
+	assert.Equal(data.T, lists+1, panel.Len())
+
+	data.Api.Error("baz")
+	panel.Activate(panel.Last())
+	err = data.Cmd.Exec()
+	assert.Nil(data.T, err)
+	log, ok = data.Api.Songlist().(*songlist.Log)
+	if assert.True(data.T, ok) {
+		assert.Equal(data.T, 2, log.Len())
+		assert.Equal(data.T, "baz", log.Song(1).StringTags["message"])
+		assert.Equal(data.T, 1, log.Cursor())
+
+		// New messages are shown when the log is refreshed, and the cursor
+		// follows the most recent message.
+		data.Api.Message("ignored")
+		data.Api.Error("qux")
+		panel.RefreshLists()
+		assert.Equal(data.T, 3, log.Len())
+		assert.Equal(data.T, "qux", log.Song(2).StringTags["message"])
+		assert.Equal(data.T, 2, log.Cursor())
+	}
+	assert.Equal(data.T, lists+1, panel.Len())
+}
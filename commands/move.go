@@ -0,0 +1,112 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/ambientsound/pms/api"
+	"github.com/ambientsound/pms/input/lexer"
+	"github.com/ambientsound/pms/songlist"
+)
+
+// Move reorders songs within a songlist.
+type Move struct {
+	newcommand
+	api      api.API
+	absolute int
+	relative int
+}
+
+// NewMove returns Move.
+func NewMove(api api.API) Command {
+	return &Move{
+		api:      api,
+		absolute: -1,
+	}
+}
+
+// Parse implements Command.
+func (cmd *Move) Parse() error {
+	list := cmd.api.Songlist()
+
+	tok, lit := cmd.ScanIgnoreWhitespace()
+	cmd.setTabCompleteVerbs(lit)
+
+	switch tok {
+	case lexer.TokenMinus, lexer.TokenPlus:
+		cmd.setTabCompleteEmpty()
+		cmd.Unscan()
+		_, i, _, err := cmd.ParseInt()
+		if err != nil {
+			return err
+		}
+		cmd.relative = i
+		return cmd.ParseEnd()
+
+	case lexer.TokenIdentifier:
+	default:
+		return fmt.Errorf("Unexpected '%v', expected number or identifier", lit)
+	}
+
+	switch lit {
+	case "up":
+		cmd.relative = -1
+	case "down":
+		cmd.relative = 1
+	case "home":
+		cmd.absolute = 0
+	case "end":
+		cmd.absolute = list.Len()
+	default:
+		cmd.Unscan()
+		_, i, _, err := cmd.ParseInt()
+		if err != nil {
+			return fmt.Errorf("Move command '%s' not recognized, and is not a number", lit)
+		}
+		if i < 1 {
+			return fmt.Errorf("Position must be 1 or greater")
+		}
+		cmd.absolute = i - 1
+	}
+
+	cmd.setTabCompleteEmpty()
+
+	return cmd.ParseEnd()
+}
+
+// Exec implements Command.
+func (cmd *Move) Exec() error {
+	var order []int
+	var err error
+
+	list := cmd.api.Songlist()
+	list.CommitVisualSelection()
+	list.DisableVisualSelection()
+	indices := list.SelectionIndices()
+
+	if cmd.absolute >= 0 {
+		order, err = songlist.MoveToOrder(list.Len(), indices, cmd.absolute)
+	} else {
+		order, err = songlist.MoveOrder(list.Len(), indices, cmd.relative)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err = list.Reorder(order); err != nil {
+		return err
+	}
+
+	cmd.api.ListChanged()
+
+	return nil
+}
+
+// setTabCompleteVerbs sets the tab complete list to the list of available sub-commands.
+func (cmd *Move) setTabCompleteVerbs(lit string) {
+	cmd.setTabComplete(lit, []string{
+		"down",
+		"end",
+		"home",
+		"up",
+	})
+}
@@ -0,0 +1,64 @@
+package commands_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/ambientsound/gompd/mpd"
+	"github.com/ambientsound/pms/commands"
+	"github.com/ambientsound/pms/song"
+	"github.com/stretchr/testify/assert"
+)
+
+var moveTests = []commands.Test{
+	// Valid forms
+	{`up`, true, initMove, testMoved("0", "2", "1", "3"), []string{}},
+	{`down`, true, initMove, testMoved("0", "1", "3", "2"), []string{}},
+	{`-2`, true, initMove, testMoved("2", "0", "1", "3"), []string{}},
+	{`+5`, true, initMove, testMoved("0", "1", "3", "2"), []string{}},
+	{`1`, true, initMove, testMoved("2", "0", "1", "3"), []string{}},
+	{`home`, true, initMove, testMoved("2", "0", "1", "3"), []string{}},
+	{`end`, true, initMove, testMoved("0", "1", "3", "2"), []string{}},
+
+	// Invalid forms
+	{``, false, nil, nil, []string{"down", "end", "home", "up"}},
+	{`0`, false, nil, nil, []string{}},
+	{`foo`, false, nil, nil, []string{}},
+	{`up 1`, false, nil, nil, []string{}},
+	{`+`, false, nil, nil, []string{}},
+
+	// Tab completion
+	{`u`, false, nil, nil, []string{"up"}},
+}
+
+func TestMove(t *testing.T) {
+	commands.TestVerb(t, "move", moveTests)
+}
+
+// initMove creates a list of four songs, and places the cursor on the third.
+func initMove(data *commands.TestData) {
+	list := data.Api.Songlist()
+	for i := 0; i < 4; i++ {
+		s := song.New()
+		s.SetTags(mpd.Attrs{"title": fmt.Sprintf("%d", i)})
+		list.Add(s)
+	}
+	list.SetCursor(2)
+}
+
+// testMoved returns a test callback that executes the command, and checks the
+// resulting song order, and that the cursor follows the moved song.
+func testMoved(titles ...string) func(data *commands.TestData) {
+	return func(data *commands.TestData) {
+		err := data.Cmd.Exec()
+		assert.Nil(data.T, err)
+
+		list := data.Api.Songlist()
+		result := make([]string, 0, list.Len())
+		for _, s := range list.Songs() {
+			result = append(result, s.StringTags["title"])
+		}
+		assert.Equal(data.T, titles, result)
+		assert.Equal(data.T, "2", list.CursorSong().StringTags["title"])
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/ambientsound/gompd/mpd"
 	"github.com/ambientsound/pms/api"
 	"github.com/ambientsound/pms/input/lexer"
+	"github.com/ambientsound/pms/songlist"
 )
 
 // Play plays songs in the MPD playlist.
@@ -57,6 +58,12 @@ func (cmd *Play) Parse() error {
 // Exec implements Command.
 func (cmd *Play) Exec() error {
 
+	// In views where the rows refer to other lists, playing the cursor or
+	// selection opens the list under the cursor instead.
+	if _, ok := cmd.api.Songlist().(songlist.Opener); ok && (cmd.cursor || cmd.selection) {
+		return openCursor(cmd.api)
+	}
+
 	// Ensure MPD connection.
 	client := cmd.api.MpdClient()
 	if client == nil {
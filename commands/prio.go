@@ -0,0 +1,57 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/ambientsound/pms/api"
+	"github.com/ambientsound/pms/songlist"
+)
+
+// Prio sets the priority of songs in MPD's queue.
+type Prio struct {
+	newcommand
+	api  api.API
+	prio int
+}
+
+// NewPrio returns Prio.
+func NewPrio(api api.API) Command {
+	return &Prio{
+		api: api,
+	}
+}
+
+// Parse implements Command.
+func (cmd *Prio) Parse() error {
+	_, lit, absolute, err := cmd.ParseInt()
+	if err != nil {
+		return err
+	}
+	if !absolute || lit < 0 || lit > 255 {
+		return fmt.Errorf("Priority must be a number between 0 and 255")
+	}
+	cmd.prio = lit
+	return cmd.ParseEnd()
+}
+
+// Exec implements Command.
+func (cmd *Prio) Exec() error {
+	list := cmd.api.Songlist()
+	queue, ok := list.(*songlist.Queue)
+	if !ok {
+		return fmt.Errorf("Priorities can only be set on songs in the queue")
+	}
+
+	list.CommitVisualSelection()
+	list.DisableVisualSelection()
+	indices := list.SelectionIndices()
+
+	if err := queue.SetPriority(indices, cmd.prio); err != nil {
+		return err
+	}
+
+	list.ClearSelection()
+	cmd.api.Message("Priority of %d songs set to %d", len(indices), cmd.prio)
+
+	return nil
+}
@@ -0,0 +1,132 @@
+package commands_test
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/ambientsound/gompd/mpd"
+	"github.com/ambientsound/pms/api"
+	"github.com/ambientsound/pms/commands"
+	"github.com/ambientsound/pms/input/lexer"
+	pms_mpd "github.com/ambientsound/pms/mpd"
+	"github.com/ambientsound/pms/song"
+	"github.com/ambientsound/pms/songlist"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var prioTests = []commands.Test{
+	// Valid forms
+	{`0`, true, nil, nil, []string{}},
+	{`1`, true, nil, nil, []string{}},
+	{`255`, true, nil, nil, []string{}},
+
+	// Invalid forms
+	{``, false, nil, nil, []string{}},
+	{`256`, false, nil, nil, []string{}},
+	{`-1`, false, nil, nil, []string{}},
+	{`+1`, false, nil, nil, []string{}},
+	{`foo`, false, nil, nil, []string{}},
+	{`1 2`, false, nil, nil, []string{}},
+}
+
+func TestPrio(t *testing.T) {
+	commands.TestVerb(t, "prio", prioTests)
+}
+
+// fakeRawMPD accepts a single connection from a raw MPD client, greeting it
+// with the given protocol version, and records the commands it receives.
+func fakeRawMPD(t *testing.T, version string) (*pms_mpd.RawClient, func() []string) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("unable to listen on loopback: %s", err)
+	}
+
+	received := make(chan []string, 1)
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			received <- nil
+			return
+		}
+		defer conn.Close()
+
+		lines := make([]string, 0)
+		fmt.Fprintf(conn, "OK MPD %s\n", version)
+		scanner := bufio.NewScanner(conn)
+		for scanner.Scan() {
+			line := scanner.Text()
+			if line == "close" {
+				continue
+			}
+			lines = append(lines, line)
+			conn.Write([]byte("OK\n"))
+		}
+		received <- lines
+	}()
+
+	client, err := pms_mpd.DialRaw("tcp", listener.Addr().String(), "")
+	if err != nil {
+		listener.Close()
+		t.Fatalf("unable to connect to fake MPD server: %s", err)
+	}
+
+	return client, func() []string {
+		client.Close()
+		listener.Close()
+		return <-received
+	}
+}
+
+// Test that the priority of the selected songs is set by their IDs, and that
+// older MPD versions without song priorities are refused.
+func TestPrioExec(t *testing.T) {
+	tests := []struct {
+		version  string
+		success  bool
+		commands []string
+	}{
+		{"0.23.5", true, []string{"prioid 10 11 13"}},
+		{"0.16.0", false, []string{}},
+	}
+
+	for i, test := range tests {
+		client, finish := fakeRawMPD(t, test.version)
+
+		list := songlist.New()
+		for pos := 0; pos < 4; pos++ {
+			s := song.New()
+			s.SetTags(mpd.Attrs{
+				"id":  fmt.Sprintf("%d", pos+10),
+				"pos": fmt.Sprintf("%d", pos),
+			})
+			list.Add(s)
+		}
+		rawClient := func() (*pms_mpd.RawClient, error) { return client, nil }
+		queue, err := songlist.NewQueue(func() *mpd.Client { return nil }, rawClient).Merge(list)
+		require.Nil(t, err)
+		queue.SetSelected(1, true)
+		queue.SetSelected(3, true)
+
+		a := api.NewTestAPIWithState(pms_mpd.PlayerStatus{}, queue)
+		a.Db().Panel().Add(queue)
+		a.Db().Panel().Activate(queue)
+
+		cmd := commands.New("prio", a)
+		cmd.SetScanner(lexer.NewScanner(strings.NewReader("10")))
+		require.Nil(t, cmd.Parse(), "test %d", i+1)
+
+		err = cmd.Exec()
+		if test.success {
+			assert.Nil(t, err, "test %d", i+1)
+			assert.False(t, queue.Selected(1), "test %d", i+1)
+		} else {
+			assert.NotNil(t, err, "test %d", i+1)
+		}
+		assert.Equal(t, test.commands, finish(), "test %d", i+1)
+	}
+}
@@ -0,0 +1,76 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ambientsound/pms/api"
+	"github.com/ambientsound/pms/input/lexer"
+	"github.com/ambientsound/pms/songlist"
+)
+
+// defaultReportTags are the tags that songs are expected to have, if no
+// tags are given to 'report tags'.
+var defaultReportTags = []string{"artist", "album", "title", "track"}
+
+// Report checks the song library for problems.
+type Report struct {
+	newcommand
+	api  api.API
+	tags []string
+}
+
+// NewReport returns Report.
+func NewReport(api api.API) Command {
+	return &Report{
+		api:  api,
+		tags: defaultReportTags,
+	}
+}
+
+// Parse implements Command.
+func (cmd *Report) Parse() error {
+	tok, lit := cmd.ScanIgnoreWhitespace()
+	cmd.setTabComplete(lit, []string{"tags"})
+	if tok != lexer.TokenIdentifier || lit != "tags" {
+		return fmt.Errorf("Unexpected '%s', expected 'tags'", lit)
+	}
+
+	tok, _ = cmd.ScanIgnoreWhitespace()
+	cmd.setTabCompleteEmpty()
+	if tok == lexer.TokenEnd {
+		return nil
+	}
+	cmd.Unscan()
+
+	var err error
+	cmd.tags, err = cmd.ParseTags(cmd.api.Songlist().CursorSong())
+
+	return err
+}
+
+// Exec implements Command.
+func (cmd *Report) Exec() error {
+	library := cmd.api.Library()
+	if library == nil {
+		return fmt.Errorf("Song library is not present.")
+	}
+
+	reports := songlist.ReportTags(library.Songs(), cmd.tags)
+	if len(reports) == 0 {
+		cmd.api.Message("No tag problems found.")
+		return nil
+	}
+
+	panel := cmd.api.Db().Panel()
+	summary := make([]string, len(reports))
+	for i, report := range reports {
+		panel.Add(report)
+		summary[i] = fmt.Sprintf("%s (%d)", report.Name(), report.Len())
+	}
+	panel.Activate(reports[0])
+
+	cmd.api.Message("Tag report: %s", strings.Join(summary, ", "))
+
+	return nil
+}
@@ -0,0 +1,27 @@
+package commands_test
+
+import (
+	"testing"
+
+	"github.com/ambientsound/pms/commands"
+)
+
+var reportTests = []commands.Test{
+	// Valid forms
+	{`tags`, true, initSongTags, nil, []string{}},
+	{`tags  `, true, initSongTags, nil, []string{}},
+	{`tags artist title`, true, initSongTags, nil, []string{"title"}},
+	{`tags musicbrainz_trackid`, true, initSongTags, nil, []string{}},
+
+	// Invalid forms
+	{``, false, nil, nil, []string{"tags"}},
+	{`foo`, false, nil, nil, []string{}},
+
+	// Tab completion
+	{`ta`, false, nil, nil, []string{"tags"}},
+	{`tags ar`, true, initSongTags, nil, []string{"artist"}},
+}
+
+func TestReport(t *testing.T) {
+	commands.TestVerb(t, "report", reportTests)
+}
@@ -41,11 +41,15 @@ func (cmd *Seek) Parse() error {
 
 // Exec implements Command.
 func (cmd *Seek) Exec() error {
+	playerStatus := cmd.api.PlayerStatus()
+	if playerStatus.Song < 0 {
+		return fmt.Errorf("Unable to seek: no song is playing")
+	}
+
 	mpdClient := cmd.api.MpdClient()
 	if mpdClient == nil {
 		return fmt.Errorf("Unable to seek: cannot communicate with MPD")
 	}
 
-	playerStatus := cmd.api.PlayerStatus()
 	return mpdClient.Seek(playerStatus.Song, cmd.absolute)
 }
@@ -3,7 +3,11 @@ package commands_test
 import (
 	"testing"
 
+	"github.com/ambientsound/pms/api"
 	"github.com/ambientsound/pms/commands"
+	pms_mpd "github.com/ambientsound/pms/mpd"
+	"github.com/ambientsound/pms/song"
+	"github.com/stretchr/testify/assert"
 )
 
 var seekTests = []commands.Test{
@@ -12,6 +16,9 @@ var seekTests = []commands.Test{
 	{`+13`, true, nil, nil, []string{}},
 	{`1329`, true, nil, nil, []string{}},
 
+	// Nothing to seek in
+	{`+13`, true, initNothingPlaying, testSeekNothingPlaying, []string{}},
+
 	// Invalid forms
 	{`nan`, false, nil, nil, []string{}},
 	{`+++1`, false, nil, nil, []string{}},
@@ -22,3 +29,20 @@ var seekTests = []commands.Test{
 func TestSeek(t *testing.T) {
 	commands.TestVerb(t, "seek", seekTests)
 }
+
+func initNothingPlaying(data *commands.TestData) {
+	status := pms_mpd.PlayerStatus{
+		Song:   song.NullPosition,
+		SongID: song.NullID,
+		State:  pms_mpd.StateStop,
+	}
+	data.Api = api.NewTestAPIWithState(status, nil)
+	data.Cmd = commands.New("seek", data.Api)
+}
+
+func testSeekNothingPlaying(data *commands.TestData) {
+	err := data.Cmd.Exec()
+	if assert.NotNil(data.T, err) {
+		assert.Contains(data.T, err.Error(), "no song is playing")
+	}
+}
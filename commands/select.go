@@ -2,25 +2,38 @@ package commands
 
 import (
 	"fmt"
+	"regexp"
+	"strings"
 
 	"github.com/ambientsound/pms/api"
 	"github.com/ambientsound/pms/input/lexer"
+	"github.com/ambientsound/pms/song"
+	"github.com/ambientsound/pms/songlist"
 )
 
 // Select manipulates song selection within a songlist.
 type Select struct {
 	newcommand
-	api    api.API
-	toggle bool
-	visual bool
-	nearby []string
+	api        api.API
+	toggle     bool
+	visual     bool
+	all        bool
+	none       bool
+	invert     bool
+	nearby     []string
+	same       []string
+	duplicates []string
+	matchTag   string
+	match      *regexp.Regexp
 }
 
 // NewSelect returns Select.
 func NewSelect(api api.API) Command {
 	return &Select{
-		api:    api,
-		nearby: make([]string, 0),
+		api:        api,
+		nearby:     make([]string, 0),
+		same:       make([]string, 0),
+		duplicates: make([]string, 0),
 	}
 }
 
@@ -39,8 +52,20 @@ func (cmd *Select) Parse() error {
 		cmd.toggle = true
 	case "visual":
 		cmd.visual = true
+	case "all":
+		cmd.all = true
+	case "none":
+		cmd.none = true
+	case "invert":
+		cmd.invert = true
 	case "nearby":
 		return cmd.parseNearby()
+	case "same":
+		return cmd.parseSame()
+	case "duplicates":
+		return cmd.parseDuplicates()
+	case "match":
+		return cmd.parseMatch()
 	default:
 		return fmt.Errorf("Unexpected '%s', expected identifier", lit)
 	}
@@ -66,6 +91,32 @@ func (cmd *Select) Exec() error {
 	case len(cmd.nearby) > 0:
 		return cmd.selectNearby()
 
+	case cmd.all:
+		list.ClearSelection()
+		selectIndices(list, func(i int, s *song.Song) bool { return true })
+		return nil
+
+	case cmd.none:
+		list.ClearSelection()
+		return nil
+
+	case cmd.invert:
+		list.CommitVisualSelection()
+		list.DisableVisualSelection()
+		for i := 0; i < list.Len(); i++ {
+			list.SetSelected(i, !list.Selected(i))
+		}
+		return nil
+
+	case len(cmd.same) > 0:
+		return cmd.selectSame()
+
+	case len(cmd.duplicates) > 0:
+		return cmd.selectDuplicates()
+
+	case cmd.match != nil:
+		return cmd.selectMatch()
+
 	default:
 		index := list.Cursor()
 		selected := list.Selected(index)
@@ -121,10 +172,132 @@ func (cmd *Select) selectNearby() error {
 	return nil
 }
 
+// parseSame parses the tags used for 'select same'.
+func (cmd *Select) parseSame() error {
+	var err error
+	list := cmd.api.Songlist()
+	cmd.same, err = cmd.ParseTags(list.CursorSong())
+	return err
+}
+
+// parseDuplicates parses the tags used for 'select duplicates'.
+func (cmd *Select) parseDuplicates() error {
+	var err error
+	list := cmd.api.Songlist()
+	cmd.duplicates, err = cmd.ParseTags(list.CursorSong())
+	return err
+}
+
+// parseMatch parses a tag and a regular expression. The regular expression
+// spans the rest of the line, and is read without interpreting any special
+// characters, so that backslash escapes are kept. Surrounding quotes are
+// removed.
+func (cmd *Select) parseMatch() error {
+	list := cmd.api.Songlist()
+
+	tok, lit := cmd.ScanIgnoreWhitespace()
+	cmd.setTabCompleteTag(lit, list.CursorSong())
+	if tok != lexer.TokenIdentifier {
+		return fmt.Errorf("Unexpected '%s', expected tag", lit)
+	}
+	cmd.matchTag = strings.ToLower(lit)
+
+	expr := strings.TrimSpace(cmd.ScanRest())
+	if len(expr) > 0 {
+		cmd.setTabCompleteEmpty()
+	}
+	if len(expr) >= 2 && strings.HasPrefix(expr, `"`) && strings.HasSuffix(expr, `"`) {
+		expr = expr[1 : len(expr)-1]
+	}
+	if len(expr) == 0 {
+		return fmt.Errorf("Unexpected END, expected regular expression")
+	}
+
+	var err error
+	cmd.match, err = regexp.Compile(expr)
+	if err != nil {
+		return fmt.Errorf("Invalid regular expression: %s", err)
+	}
+
+	return nil
+}
+
+// selectSame selects all songs in the list that have the same tag values as
+// the song under the cursor.
+func (cmd *Select) selectSame() error {
+	list := cmd.api.Songlist()
+	cursorSong := list.CursorSong()
+	if cursorSong == nil {
+		return fmt.Errorf("Can't select songs with the same tags; no song under cursor")
+	}
+
+	key := songlist.GroupKey(cursorSong, cmd.same)
+	count := selectIndices(list, func(i int, s *song.Song) bool {
+		return songlist.GroupKey(s, cmd.same) == key
+	})
+
+	cmd.api.Message("%d songs selected", count)
+	return nil
+}
+
+// selectDuplicates selects songs that have the same tag values as a song
+// earlier in the list. The first occurrence is not selected.
+func (cmd *Select) selectDuplicates() error {
+	list := cmd.api.Songlist()
+	seen := make(map[string]bool)
+
+	count := selectIndices(list, func(i int, s *song.Song) bool {
+		key := songlist.GroupKey(s, cmd.duplicates)
+		if seen[key] {
+			return true
+		}
+		seen[key] = true
+		return false
+	})
+
+	cmd.api.Message("%d duplicate songs selected", count)
+	return nil
+}
+
+// selectMatch selects songs whose tag matches a regular expression.
+func (cmd *Select) selectMatch() error {
+	list := cmd.api.Songlist()
+	count := selectIndices(list, func(i int, s *song.Song) bool {
+		return cmd.match.MatchString(s.StringTags[cmd.matchTag])
+	})
+
+	cmd.api.Message("%d songs selected", count)
+	return nil
+}
+
+// selectIndices adds all songs for which the function returns true to the
+// selection, and returns the number of matching songs. Any visual selection is
+// committed first.
+func selectIndices(list songlist.Songlist, f func(int, *song.Song) bool) int {
+	list.CommitVisualSelection()
+	list.DisableVisualSelection()
+
+	count := 0
+	for i, s := range list.Songs() {
+		if f(i, s) {
+			list.SetSelected(i, true)
+			count++
+		}
+	}
+
+	return count
+}
+
 // setTabCompleteVerbs sets the tab complete list to the list of available sub-commands.
 func (cmd *Select) setTabCompleteVerbs(lit string) {
 	cmd.setTabComplete(lit, []string{
+		"all",
+		"duplicates",
+		"invert",
+		"match",
 		"nearby",
+		"none",
+		"same",
 		"toggle",
 		"visual",
 	})
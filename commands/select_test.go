@@ -3,7 +3,10 @@ package commands_test
 import (
 	"testing"
 
+	"github.com/ambientsound/gompd/mpd"
 	"github.com/ambientsound/pms/commands"
+	"github.com/ambientsound/pms/song"
+	"github.com/stretchr/testify/assert"
 )
 
 var selectTests = []commands.Test{
@@ -11,24 +14,114 @@ var selectTests = []commands.Test{
 	{`visual`, true, nil, nil, []string{}},
 	{`toggle`, true, nil, nil, []string{}},
 	{`nearby artist tit`, true, initSongTags, nil, []string{"title"}},
+	{`all`, true, initSelect, testSelected(0, 1, 2, 3, 4), []string{}},
+	{`none`, true, initSelect, testSelected(), []string{}},
+	{`invert`, true, initSelect, testSelected(0, 2, 3, 4), []string{}},
+	{`match title ^[ab]$`, true, initSelect, testSelected(0, 1, 2), []string{}},
+	{`match artist "fo|ba"`, true, initSelect, testSelected(0, 1, 2, 3, 4), []string{}},
+	{`match artist ^fo{2}$`, true, initSelect, testSelected(0, 1, 2, 4), []string{}},
+	{`match file \.flac$`, true, initSelectFiles, testSelected(0, 3), []string{}},
+	{`match file "^[a-z]+-\d"`, true, initSelectFiles, testSelected(2), []string{}},
+	{`match file x|#`, true, initSelectFiles, testSelected(1, 2), []string{}},
+	{`same artist`, true, initSelect, testSelected(0, 1, 2, 4), []string{"artist"}},
+	{`duplicates artist title`, true, initSelect, testSelected(1, 2), []string{"title"}},
+	{`same artist`, true, initSelectCase, testSelected(0, 1, 2, 4, 5), []string{"artist"}},
+	{`duplicates artist title`, true, initSelectCase, testSelected(1, 2, 5), []string{"title"}},
 
 	// Invalid forms
 	{`foo`, false, nil, nil, []string{}},
 	{`visual 1`, false, nil, nil, []string{}},
 	{`toggle 1`, false, nil, nil, []string{}},
+	{`all 1`, false, nil, nil, []string{}},
 	{`nearby`, false, nil, nil, []string{}},
+	{`same`, false, nil, nil, []string{}},
+	{`duplicates`, false, nil, nil, []string{}},
+	{`match`, false, nil, nil, []string{}},
+	{`match title`, false, nil, nil, []string{}},
+	{`match title (`, false, nil, nil, []string{}},
 
 	// Tab completion
 	{``, false, nil, nil, []string{
+		"all",
+		"duplicates",
+		"invert",
+		"match",
 		"nearby",
+		"none",
+		"same",
 		"toggle",
 		"visual",
 	}},
 	{`t`, false, nil, nil, []string{
 		"toggle",
 	}},
+	{`match tit`, false, initSongTags, nil, []string{
+		"title",
+	}},
 }
 
 func TestSelect(t *testing.T) {
 	commands.TestVerb(t, "select", selectTests)
 }
+
+// initSelect creates a list of songs, where the song at index 1 is selected.
+func initSelect(data *commands.TestData) {
+	list := data.Api.Songlist()
+	tags := [][2]string{
+		{"foo", "a"},
+		{"bar", "b"},
+		{"foo", "a"},
+		{"baz", "c"},
+		{"foo", "d"},
+	}
+	for _, t := range tags {
+		s := song.New()
+		s.SetTags(mpd.Attrs{
+			"artist": t[0],
+			"title":  t[1],
+		})
+		list.Add(s)
+	}
+	list.SetSelected(1, true)
+}
+
+// initSelectCase adds a song to the list created by initSelect, with the
+// same tags as the first song in a different letter case.
+func initSelectCase(data *commands.TestData) {
+	initSelect(data)
+	s := song.New()
+	s.SetTags(mpd.Attrs{
+		"artist": "FOO",
+		"title":  "A",
+	})
+	data.Api.Songlist().Add(s)
+}
+
+// initSelectFiles creates a list of songs with file names containing regular
+// expression special characters.
+func initSelectFiles(data *commands.TestData) {
+	list := data.Api.Songlist()
+	for _, file := range []string{"a.flac", "bxflac", "foo-1 #2.ogg", "b.flac"} {
+		s := song.New()
+		s.SetTags(mpd.Attrs{"file": file})
+		list.Add(s)
+	}
+}
+
+// testSelected returns a test callback that executes the command, and checks
+// that exactly the given indices are selected.
+func testSelected(indices ...int) func(data *commands.TestData) {
+	return func(data *commands.TestData) {
+		err := data.Cmd.Exec()
+		assert.Nil(data.T, err)
+
+		list := data.Api.Songlist()
+		selected := make([]int, 0, len(indices))
+		for i := 0; i < list.Len(); i++ {
+			if list.Selected(i) {
+				selected = append(selected, i)
+			}
+		}
+		assert.ElementsMatch(data.T, indices, selected)
+	}
+}
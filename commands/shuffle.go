@@ -0,0 +1,135 @@
+package commands
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/ambientsound/pms/api"
+	"github.com/ambientsound/pms/input/lexer"
+	"github.com/ambientsound/pms/songlist"
+)
+
+// albumTags are the tags that identify an album when shuffling albums.
+var albumTags = []string{"albumartist", "album"}
+
+// Shuffle randomizes the order of songs in a songlist.
+type Shuffle struct {
+	newcommand
+	api    api.API
+	albums bool
+}
+
+// NewShuffle returns Shuffle.
+func NewShuffle(api api.API) Command {
+	return &Shuffle{
+		api: api,
+	}
+}
+
+// Parse implements Command.
+func (cmd *Shuffle) Parse() error {
+	tok, lit := cmd.ScanIgnoreWhitespace()
+	cmd.setTabCompleteVerbs(lit)
+
+	switch tok {
+	case lexer.TokenEnd:
+		return nil
+	case lexer.TokenIdentifier:
+	default:
+		return fmt.Errorf("Unexpected '%s', expected identifier", lit)
+	}
+
+	switch lit {
+	case "albums":
+		cmd.albums = true
+	default:
+		return fmt.Errorf("Unexpected '%s', expected identifier", lit)
+	}
+
+	cmd.setTabCompleteEmpty()
+
+	return cmd.ParseEnd()
+}
+
+// Exec implements Command.
+func (cmd *Shuffle) Exec() error {
+	var order []int
+	var err error
+
+	list := cmd.api.Songlist()
+	list.CommitVisualSelection()
+	list.DisableVisualSelection()
+
+	indices := cmd.indices(list)
+	if len(indices) < 2 {
+		return fmt.Errorf("Need at least two songs to shuffle")
+	}
+
+	// MPD can shuffle contiguous ranges of the queue by itself.
+	if queue, ok := list.(*songlist.Queue); ok && !cmd.albums && contiguous(indices) {
+		start, end := indices[0], indices[len(indices)-1]+1
+		if len(indices) == queue.Len() {
+			start, end = -1, -1
+		}
+		if err = queue.ShuffleRange(start, end); err != nil {
+			return err
+		}
+		cmd.api.Message("Shuffled %d songs", len(indices))
+		return nil
+	}
+
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	if cmd.albums {
+		order, err = songlist.ShuffleGroupsOrder(list.Songs(), indices, albumTags, r)
+	} else {
+		order, err = songlist.ShuffleOrder(list.Len(), indices, r)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err = list.Reorder(order); err != nil {
+		return err
+	}
+
+	cmd.api.ListChanged()
+	cmd.api.Message("Shuffled %d songs", len(indices))
+
+	return nil
+}
+
+// indices returns the indices of the selected songs. If there is no selection,
+// the indices of all songs in the list are returned.
+func (cmd *Shuffle) indices(list songlist.Songlist) []int {
+	indices := make([]int, 0, list.Len())
+	for i := 0; i < list.Len(); i++ {
+		if list.Selected(i) {
+			indices = append(indices, i)
+		}
+	}
+	if len(indices) > 0 {
+		return indices
+	}
+	for i := 0; i < list.Len(); i++ {
+		indices = append(indices, i)
+	}
+	return indices
+}
+
+// contiguous returns true if a sorted slice of indices has no gaps.
+func contiguous(indices []int) bool {
+	for i := 1; i < len(indices); i++ {
+		if indices[i] != indices[i-1]+1 {
+			return false
+		}
+	}
+	return true
+}
+
+// setTabCompleteVerbs sets the tab complete list to the list of available sub-commands.
+func (cmd *Shuffle) setTabCompleteVerbs(lit string) {
+	cmd.setTabComplete(lit, []string{
+		"albums",
+	})
+}
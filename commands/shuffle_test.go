@@ -0,0 +1,58 @@
+package commands_test
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+
+	"github.com/ambientsound/gompd/mpd"
+	"github.com/ambientsound/pms/commands"
+	"github.com/ambientsound/pms/song"
+	"github.com/stretchr/testify/assert"
+)
+
+var shuffleTests = []commands.Test{
+	// Valid forms
+	{``, true, initShuffle, testShuffled, []string{"albums"}},
+	{`albums`, true, initShuffle, testShuffled, []string{}},
+
+	// Invalid forms
+	{`foo`, false, nil, nil, []string{}},
+	{`albums foo`, false, nil, nil, []string{}},
+
+	// Tab completion
+	{`al`, false, nil, nil, []string{"albums"}},
+}
+
+func TestShuffle(t *testing.T) {
+	commands.TestVerb(t, "shuffle", shuffleTests)
+}
+
+// initShuffle creates a list of three albums with three tracks each.
+func initShuffle(data *commands.TestData) {
+	list := data.Api.Songlist()
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			s := song.New()
+			s.SetTags(mpd.Attrs{
+				"album": fmt.Sprintf("album %d", i),
+				"title": fmt.Sprintf("%d%d", i, j),
+			})
+			list.Add(s)
+		}
+	}
+}
+
+// testShuffled executes the command, and checks that all songs are still in
+// the list.
+func testShuffled(data *commands.TestData) {
+	err := data.Cmd.Exec()
+	assert.Nil(data.T, err)
+
+	titles := make([]string, 0)
+	for _, s := range data.Api.Songlist().Songs() {
+		titles = append(titles, s.StringTags["title"])
+	}
+	sort.Strings(titles)
+	assert.Equal(data.T, []string{"00", "01", "02", "10", "11", "12", "20", "21", "22"}, titles)
+}
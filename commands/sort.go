@@ -6,6 +6,8 @@ import (
 
 	"github.com/ambientsound/pms/api"
 	"github.com/ambientsound/pms/input/lexer"
+	"github.com/ambientsound/pms/song"
+	"github.com/ambientsound/pms/songlist"
 )
 
 // Sort sorts songlists.
@@ -38,10 +40,10 @@ func (cmd *Sort) Parse() error {
 			cmd.setTabCompleteTag("", song)
 			continue
 
-		case lexer.TokenIdentifier:
+		case lexer.TokenIdentifier, lexer.TokenMinus:
 			// Sort by tags specified on the command line
 			cmd.Unscan()
-			cmd.tags, err = cmd.ParseTags(song)
+			cmd.tags, err = cmd.parseKeys(song)
 			return err
 
 		case lexer.TokenEnd:
@@ -56,6 +58,50 @@ func (cmd *Sort) Parse() error {
 	}
 }
 
+// parseKeys parses a set of sort keys until the end of the line. Sort keys are
+// tag names, optionally prefixed with a minus sign for descending order.
+func (cmd *Sort) parseKeys(s *song.Song) ([]string, error) {
+	cmd.setTabCompleteEmpty()
+	keys := make([]string, 0)
+	key := ""
+
+	for {
+		tok, lit := cmd.Scan()
+
+		switch tok {
+		case lexer.TokenWhitespace:
+			if len(key) > 0 {
+				keys = append(keys, strings.ToLower(key))
+			}
+			key = ""
+		case lexer.TokenEnd, lexer.TokenComment:
+			if len(key) > 0 {
+				keys = append(keys, strings.ToLower(key))
+			}
+			_, err := songlist.ParseSortKeys(keys)
+			return keys, err
+		default:
+			key += lit
+		}
+
+		cmd.setTabCompleteKey(key, s)
+	}
+}
+
+// setTabCompleteKey sets the tab complete list to the tag keys in a song,
+// keeping any minus sign in front of the tag name.
+func (cmd *Sort) setTabCompleteKey(key string, s *song.Song) {
+	if !strings.HasPrefix(key, "-") || s == nil {
+		cmd.setTabCompleteTag(key, s)
+		return
+	}
+	tags := s.TagKeys()
+	for i := range tags {
+		tags[i] = "-" + tags[i]
+	}
+	cmd.setTabComplete(key, tags)
+}
+
 // Exec implements Command.
 func (cmd *Sort) Exec() error {
 	list := cmd.api.Songlist()
@@ -17,9 +17,13 @@ var sortTests = []commands.Test{
 	{`artist title`, true, initSort, testSorting, []string{"title"}},
 	{`complex-tag`, true, initSort, testSorting, []string{"complex-tag"}},
 	{`tag&|!{ more-tags "x y z"`, true, initSort, testSorting, []string{}},
+	{`-title artist`, true, initSort, testSortedBy("artist 1", "title 9"), []string{"artist"}},
+	{`title -artist`, true, initSort, testSortedBy("artist 2", "title 1"), []string{"-artist"}},
+	{`-ti`, true, initSort, nil, []string{"-title"}},
 
 	// Invalid forms
 	{`$`, false, nil, nil, []string{}},
+	{`-`, false, nil, nil, []string{}},
 }
 
 func TestSort(t *testing.T) {
@@ -32,6 +36,18 @@ func testSorting(data *commands.TestData) {
 	assert.Nil(data.T, err)
 }
 
+// testSortedBy returns a test callback that executes the command, and checks
+// the artist and title of the first song in the list.
+func testSortedBy(artist, title string) func(data *commands.TestData) {
+	return func(data *commands.TestData) {
+		err := data.Cmd.Exec()
+		assert.Nil(data.T, err)
+		first := data.Api.Songlist().Song(0)
+		assert.Equal(data.T, artist, first.StringTags["artist"])
+		assert.Equal(data.T, title, first.StringTags["title"])
+	}
+}
+
 func initSort(data *commands.TestData) {
 	// Set up the sort option
 	// FIXME
@@ -0,0 +1,49 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/ambientsound/gompd/mpd"
+	"github.com/ambientsound/pms/api"
+	"github.com/ambientsound/pms/songlist"
+)
+
+// Stats opens a view of MPD server statistics and song library aggregates.
+type Stats struct {
+	newcommand
+	api api.API
+}
+
+// NewStats returns Stats.
+func NewStats(api api.API) Command {
+	return &Stats{
+		api: api,
+	}
+}
+
+// Parse implements Command.
+func (cmd *Stats) Parse() error {
+	return cmd.ParseEnd()
+}
+
+// Exec implements Command.
+func (cmd *Stats) Exec() error {
+	library := cmd.api.Library()
+	if library == nil {
+		return fmt.Errorf("Song library is not present.")
+	}
+
+	var server mpd.Attrs
+	if client := cmd.api.MpdClient(); client != nil {
+		var err error
+		server, err = client.Stats()
+		if err != nil {
+			return fmt.Errorf("Error while retrieving statistics from MPD: %s", err)
+		}
+	}
+
+	stats := songlist.NewStats(server, library)
+	cmd.api.Db().Panel().Activate(stats)
+
+	return nil
+}
@@ -0,0 +1,57 @@
+package commands_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ambientsound/gompd/mpd"
+	"github.com/ambientsound/pms/commands"
+	"github.com/ambientsound/pms/songlist"
+	"github.com/stretchr/testify/assert"
+)
+
+var statsTests = []commands.Test{
+	// Valid forms
+	{``, true, nil, testRequiresLibrary, []string{}},
+	{``, true, initLibrary, testStatsOpened, []string{}},
+
+	// Invalid forms
+	{`foo`, false, nil, nil, []string{}},
+}
+
+func TestStats(t *testing.T) {
+	commands.TestVerb(t, "stats", statsTests)
+}
+
+func initStatsView(data *commands.TestData) {
+	panel := data.Api.Db().Panel()
+	panel.Activate(songlist.NewStats(mpd.Attrs{"artists": "1"}, songlist.NewLibrary()))
+}
+
+// testStatsOpened checks that the library aggregates are shown, and that the
+// server statistics are left out when MPD is not connected.
+func testStatsOpened(data *commands.TestData) {
+	err := data.Cmd.Exec()
+	assert.Nil(data.T, err)
+
+	stats, ok := data.Api.Songlist().(*songlist.Stats)
+	if !assert.True(data.T, ok) {
+		return
+	}
+
+	rows := make([]string, stats.Len())
+	for i, row := range stats.Songs() {
+		rows[i] = strings.Join([]string{row.StringTags["section"], row.StringTags["name"], row.StringTags["count"]}, "/")
+	}
+	assert.Equal(data.T, []string{
+		"Artists by tracks/foo/2",
+		"Artists by tracks/bar/1",
+		"Artists by duration/foo/2",
+		"Artists by duration/bar/1",
+	}, rows)
+
+	artist, err := stats.Open(0)
+	if assert.Nil(data.T, err) {
+		assert.Equal(data.T, []string{"foo/a.flac", "foo/b.flac"}, songFiles(artist))
+	}
+}
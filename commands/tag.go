@@ -0,0 +1,130 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ambientsound/pms/api"
+	"github.com/ambientsound/pms/input/lexer"
+	"github.com/ambientsound/pms/song"
+)
+
+// Tag defines virtual tags, which are derived from other song tags.
+type Tag struct {
+	newcommand
+	api      api.API
+	define   *song.VirtualTag
+	undefine string
+}
+
+// NewTag returns Tag.
+func NewTag(api api.API) Command {
+	return &Tag{
+		api: api,
+	}
+}
+
+// Parse implements Command.
+func (cmd *Tag) Parse() error {
+	tok, lit := cmd.ScanIgnoreWhitespace()
+	cmd.setTabCompleteVerbs(lit)
+	if tok != lexer.TokenIdentifier {
+		return fmt.Errorf("Unexpected '%s', expected identifier", lit)
+	}
+
+	switch lit {
+	case "define":
+		return cmd.parseDefine()
+	case "undefine":
+		return cmd.parseUndefine()
+	default:
+		return fmt.Errorf("Unexpected '%s', expected 'define' or 'undefine'", lit)
+	}
+}
+
+// parseDefine parses a tag name, an equals sign, and a tag definition. The
+// definition spans the rest of the line, and is not subject to any escaping
+// except for an optional pair of surrounding quotes.
+func (cmd *Tag) parseDefine() error {
+	tok, lit := cmd.ScanIgnoreWhitespace()
+	cmd.setTabCompleteEmpty()
+	if tok != lexer.TokenIdentifier {
+		return fmt.Errorf("Unexpected '%s', expected tag name", lit)
+	}
+	name := lit
+
+	tok, lit = cmd.ScanIgnoreWhitespace()
+	if tok != lexer.TokenEqual {
+		return fmt.Errorf("Unexpected '%s', expected '='", lit)
+	}
+
+	expr := strings.TrimSpace(cmd.ScanRest())
+	if len(expr) >= 2 && strings.HasPrefix(expr, `"`) && strings.HasSuffix(expr, `"`) {
+		expr = strings.Replace(expr[1:len(expr)-1], `\"`, `"`, -1)
+	}
+	if len(expr) == 0 {
+		return fmt.Errorf("Unexpected END, expected tag definition")
+	}
+
+	var err error
+	cmd.define, err = song.ParseVirtualTag(name, expr)
+
+	return err
+}
+
+// parseUndefine parses the name of a virtual tag.
+func (cmd *Tag) parseUndefine() error {
+	tok, lit := cmd.ScanIgnoreWhitespace()
+	cmd.setTabCompleteVirtualTags(lit)
+	if tok != lexer.TokenIdentifier {
+		return fmt.Errorf("Unexpected '%s', expected tag name", lit)
+	}
+	cmd.undefine = strings.ToLower(lit)
+	return cmd.ParseEnd()
+}
+
+// Exec implements Command.
+func (cmd *Tag) Exec() error {
+	tagging := cmd.api.Db().Tagging()
+	if cmd.define != nil {
+		tagging.Define(cmd.define)
+	} else if !tagging.Undefine(cmd.undefine) {
+		return fmt.Errorf("Virtual tag '%s' is not defined", cmd.undefine)
+	}
+
+	cmd.refresh()
+
+	return nil
+}
+
+// refresh recalculates the virtual tags of all songs in all songlists, and
+// updates the songlist columns accordingly.
+func (cmd *Tag) refresh() {
+	tagging := cmd.api.Db().Tagging()
+	cmd.api.Db().UpdateSongs(func(s *song.Song) {
+		if len(cmd.undefine) > 0 {
+			s.RemoveTag(cmd.undefine)
+		}
+		s.FillVirtualTags(tagging)
+		s.FillSortTags()
+	})
+	cmd.api.ListChanged()
+}
+
+// setTabCompleteVerbs sets the tab complete list to the list of available sub-commands.
+func (cmd *Tag) setTabCompleteVerbs(lit string) {
+	cmd.setTabComplete(lit, []string{
+		"define",
+		"undefine",
+	})
+}
+
+// setTabCompleteVirtualTags sets the tab complete list to the names of all virtual tags.
+func (cmd *Tag) setTabCompleteVirtualTags(lit string) {
+	defs := cmd.api.Db().Tagging().VirtualTags()
+	names := make([]string, len(defs))
+	for i := range defs {
+		names[i] = defs[i].Name
+	}
+	cmd.setTabComplete(lit, names)
+}
@@ -0,0 +1,83 @@
+package commands_test
+
+import (
+	"testing"
+
+	"github.com/ambientsound/gompd/mpd"
+	"github.com/ambientsound/pms/commands"
+	"github.com/ambientsound/pms/song"
+	"github.com/stretchr/testify/assert"
+)
+
+var tagTests = []commands.Test{
+	// Valid forms
+	{`define ext = file:regex(\.(\w+)$)`, true, initTag, testTagDefined("ext", "flac"), []string{}},
+	{`define ext=file:regex(\.(\w+)$)`, true, initTag, testTagDefined("ext", "flac"), []string{}},
+	{`define artistalbum = "${albumartist|artist} – ${album}"`, true, initTag, testTagDefined("artistalbum", "foo – bar"), []string{}},
+	{`define quoted = "\"${album}\""`, true, initTag, testTagDefined("quoted", `"bar"`), []string{}},
+	{`undefine foo`, true, nil, testTagUndefineMissing, []string{}},
+
+	// Invalid forms
+	{``, false, nil, nil, []string{"define", "undefine"}},
+	{`foo`, false, nil, nil, []string{}},
+	{`define`, false, nil, nil, []string{}},
+	{`define foo`, false, nil, nil, []string{}},
+	{`define foo bar`, false, nil, nil, []string{}},
+	{`define foo =`, false, nil, nil, []string{}},
+	{`define foo = ""`, false, nil, nil, []string{}},
+	{`define foo = ${bar`, false, nil, nil, []string{}},
+	{`define file = ${bar}`, false, nil, nil, []string{}},
+	{`undefine`, false, nil, nil, []string{}},
+	{`undefine foo bar`, false, nil, nil, []string{}},
+
+	// Tab completion
+	{`def`, false, nil, nil, []string{"define"}},
+	{`und`, false, nil, nil, []string{"undefine"}},
+}
+
+func TestTag(t *testing.T) {
+	commands.TestVerb(t, "tag", tagTests)
+}
+
+func initTag(data *commands.TestData) {
+	s := song.New()
+	s.SetTags(mpd.Attrs{
+		"file":   "foo/bar.flac",
+		"artist": "foo",
+		"album":  "bar",
+	})
+	data.Api.Songlist().Add(s)
+}
+
+// testTagDefined checks that songs in existing lists get the virtual tag,
+// and that the tag is removed again when undefined.
+func testTagDefined(tag, value string) func(data *commands.TestData) {
+	return func(data *commands.TestData) {
+		err := data.Cmd.Exec()
+		assert.Nil(data.T, err)
+
+		s := data.Api.Songlist().Song(0)
+		assert.Equal(data.T, value, s.StringTags[tag])
+		assert.Equal(data.T, len([]rune(value)), data.Api.Songlist().Columns([]string{tag})[0].MaxWidth(), "column is not updated")
+
+		undefine := commands.New("tag", data.Api)
+		commands.TestCommand(&commands.TestData{
+			T:   data.T,
+			Cmd: undefine,
+			Api: data.Api,
+			Test: commands.Test{
+				Input:       "undefine " + tag,
+				Success:     true,
+				TabComplete: []string{tag},
+			},
+		})
+		err = undefine.Exec()
+		assert.Nil(data.T, err)
+		assert.NotContains(data.T, s.StringTags, tag)
+	}
+}
+
+func testTagUndefineMissing(data *commands.TestData) {
+	err := data.Cmd.Exec()
+	assert.NotNil(data.T, err)
+}
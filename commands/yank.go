@@ -27,6 +27,10 @@ func (cmd *Yank) Parse() error {
 // Exec implements Command.
 func (cmd *Yank) Exec() error {
 	list := cmd.api.Songlist()
+	if err := requireSongs(list); err != nil {
+		return err
+	}
+
 	selection := list.Selection()
 	indices := list.SelectionIndices()
 	len := len(indices)
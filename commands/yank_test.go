@@ -11,6 +11,12 @@ var yankTests = []commands.Test{
 	{``, true, nil, nil, []string{}},
 	{`    `, true, nil, nil, []string{}},
 
+	// The lists view does not contain songs.
+	{``, true, initListsView, testRefusedInListsView, []string{}},
+
+	// The statistics view does not contain songs.
+	{``, true, initStatsView, testRefusedInListsView, []string{}},
+
 	// Invalid forms
 	{`foo`, false, nil, nil, []string{}},
 	{`foo bar`, false, nil, nil, []string{}},
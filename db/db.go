@@ -2,6 +2,7 @@
 package db
 
 import (
+	"github.com/ambientsound/pms/message"
 	pms_mpd "github.com/ambientsound/pms/mpd"
 	"github.com/ambientsound/pms/options"
 	"github.com/ambientsound/pms/song"
@@ -18,24 +19,45 @@ type Instance struct {
 	// song lists
 	queue      *songlist.Queue
 	library    *songlist.Library
+	history    *songlist.History
 	songlists  []songlist.Songlist
 	clipboards map[string]songlist.Songlist
 	options    *options.Options
+	tagging    *song.Tagging
 
 	// panels
 	left  *songlist.Collection
 	right *songlist.Collection
+
+	// message log
+	messages *message.Buffer
 }
 
+// messageBufferSize is the maximum number of messages kept in the message log.
+const messageBufferSize = 1000
+
 // New returns Instance.
 func New() *Instance {
 	return &Instance{
 		clipboards: make(map[string]songlist.Songlist, 0),
 		left:       songlist.NewCollection(),
 		right:      songlist.NewCollection(),
+		messages:   message.NewBuffer(messageBufferSize),
+		tagging:    song.NewTagging(),
 	}
 }
 
+// Tagging returns the tag separator and virtual tag definitions used to
+// calculate song tags.
+func (db *Instance) Tagging() *song.Tagging {
+	return db.tagging
+}
+
+// Messages returns the message log.
+func (db *Instance) Messages() *message.Buffer {
+	return db.messages
+}
+
 // Clipboard returns a named clipboard.
 func (db *Instance) Clipboard(key string) songlist.Songlist {
 	_, ok := db.clipboards[key]
@@ -75,6 +97,16 @@ func (db *Instance) SetLibrary(library *songlist.Library) {
 	db.library = library
 }
 
+// History returns the listening history.
+func (db *Instance) History() *songlist.History {
+	return db.history
+}
+
+// SetHistory sets the listening history.
+func (db *Instance) SetHistory(history *songlist.History) {
+	db.history = history
+}
+
 // PlayerStatus returns a copy of the current MPD player status as seen by PMS.
 func (db *Instance) PlayerStatus() pms_mpd.PlayerStatus {
 	return db.mpdStatus
@@ -85,6 +117,65 @@ func (db *Instance) SetPlayerStatus(p pms_mpd.PlayerStatus) {
 	db.mpdStatus = p
 }
 
+// Songlists returns all songlists known to PMS, including the library, the
+// queue, the history, clipboards, and the lists in each panel. Each songlist
+// is returned only once.
+func (db *Instance) Songlists() []songlist.Songlist {
+	lists := make([]songlist.Songlist, 0)
+	seen := make(map[songlist.Songlist]bool)
+	add := func(list songlist.Songlist) {
+		if list == nil || seen[list] {
+			return
+		}
+		seen[list] = true
+		lists = append(lists, list)
+	}
+
+	if db.library != nil {
+		add(db.library)
+	}
+	if db.queue != nil {
+		add(db.queue)
+	}
+	if db.history != nil {
+		add(db.history)
+	}
+	for _, list := range db.clipboards {
+		add(list)
+	}
+	for _, panel := range []*songlist.Collection{db.left, db.right} {
+		for i := 0; i < panel.Len(); i++ {
+			list, _ := panel.Songlist(i)
+			add(list)
+		}
+	}
+
+	return lists
+}
+
+// UpdateSongs calls a function for every song in every songlist, and for the
+// current song. The column widths of all songlists are recalculated afterwards.
+func (db *Instance) UpdateSongs(update func(*song.Song)) {
+	// Songs are read by the indexer while the library is being indexed, so a
+	// running indexing job is aborted, and started again afterwards.
+	reindex := db.library != nil && db.library.CancelReIndex()
+
+	for _, list := range db.Songlists() {
+		for _, s := range list.Songs() {
+			update(s)
+		}
+		list.UpdateColumns()
+	}
+
+	if db.currentSong != nil {
+		update(db.currentSong)
+	}
+
+	if reindex {
+		db.library.ReIndex()
+	}
+}
+
 // Panel returns the active panel. At the moment, there is only one panel.
 func (db *Instance) Panel() *songlist.Collection {
 	return db.Left()
@@ -21,6 +21,11 @@ const INDEX_BATCH_SIZE int = 1000
 
 const SEARCH_SCORE_THRESHOLD float64 = 0.5
 
+// INDEX_SCHEMA_VERSION must be increased whenever the index mapping or the
+// indexed song documents change. Indexes written with another schema version
+// are rebuilt.
+const INDEX_SCHEMA_VERSION int = 1
+
 type Index struct {
 	bleveIndex bleve.Index
 	path       string
@@ -37,7 +42,42 @@ func createDirectory(dir string) error {
 // New opens a Bleve index and returns Index. In case an index is not found at
 // the given path, a new one is created. In case of an error, nil is returned,
 // and the error object set accordingly.
-func New(basePath string) (*Index, error) {
+//
+// The index can only be opened by one process at a time. If timeout is
+// positive, New gives up waiting for another process to release the index
+// after that amount of time. Otherwise, it waits indefinitely.
+func New(basePath string, timeout time.Duration) (*Index, error) {
+	if timeout <= 0 {
+		return newIndex(basePath)
+	}
+
+	type result struct {
+		index *Index
+		err   error
+	}
+
+	done := make(chan result, 1)
+	go func() {
+		i, err := newIndex(basePath)
+		done <- result{i, err}
+	}()
+
+	select {
+	case r := <-done:
+		return r.index, r.err
+	case <-time.After(timeout):
+		// Close the index if the lock is released later on.
+		go func() {
+			if r := <-done; r.err == nil {
+				r.index.Close()
+			}
+		}()
+		return nil, fmt.Errorf("Search index at %s is locked by a running pms; close it and try again", basePath)
+	}
+}
+
+// newIndex opens or creates the index, waiting for any lock to be released.
+func newIndex(basePath string) (*Index, error) {
 	var err error
 
 	timer := time.Now()
@@ -78,10 +118,18 @@ func New(basePath string) (*Index, error) {
 		if err != nil {
 			return nil, fmt.Errorf("while opening index at %s: %s", i.indexPath, err)
 		}
-		i.version, err = i.readVersion()
+		var schema int
+		i.version, schema, err = i.readState()
 		if err != nil {
 			console.Log("index state file is broken: %s", err)
 		}
+
+		if schema != INDEX_SCHEMA_VERSION {
+			console.Log("Search index has schema version %d, expected %d; rebuilding index", schema, INDEX_SCHEMA_VERSION)
+			if err = i.recreate(); err != nil {
+				return nil, err
+			}
+		}
 	}
 
 	console.Log("Opened search index in %s", time.Since(timer).String())
@@ -89,6 +137,28 @@ func New(basePath string) (*Index, error) {
 	return i, nil
 }
 
+// recreate replaces the open index with a new, empty one, and resets the MPD
+// library version.
+func (i *Index) recreate() error {
+	var err error
+
+	if err = i.bleveIndex.Close(); err != nil {
+		return fmt.Errorf("while closing index at %s: %s", i.indexPath, err)
+	}
+	if err = os.RemoveAll(i.indexPath); err != nil {
+		return fmt.Errorf("while removing index at %s: %s", i.indexPath, err)
+	}
+	i.bleveIndex, err = create(i.indexPath)
+	if err != nil {
+		return fmt.Errorf("while creating index at %s: %s", i.indexPath, err)
+	}
+	if err = i.SetVersion(0); err != nil {
+		return fmt.Errorf("while zeroing out library version at %s: %s", i.statePath, err)
+	}
+
+	return nil
+}
+
 // Close closes a Bleve index.
 func (i *Index) Close() error {
 	return i.bleveIndex.Close()
@@ -126,37 +196,48 @@ func Path(host, port string) string {
 	return path.Join(cacheDir, host, port)
 }
 
-// SetVersion writes the MPD library version to the state file.
+// SetVersion writes the MPD library version and the index schema version to
+// the state file.
 func (i *Index) SetVersion(version int) error {
 	file, err := os.Create(i.statePath)
 	if err != nil {
 		return err
 	}
 	defer file.Close()
-	str := fmt.Sprintf("%d\n", version)
+	str := fmt.Sprintf("%d\n%d\n", version, INDEX_SCHEMA_VERSION)
 	file.WriteString(str)
 	i.version = version
 	return nil
 }
 
-// readVersion reads the MPD library version from the state file.
-func (i *Index) readVersion() (int, error) {
+// readState reads the MPD library version and the index schema version from
+// the state file. State files written before the schema was versioned only
+// contain the library version, and have schema version zero.
+func (i *Index) readState() (version, schema int, err error) {
 	file, err := os.Open(i.statePath)
 	if err != nil {
-		return 0, err
+		return 0, 0, err
 	}
 	defer file.Close()
 
+	values := make([]int, 0, 2)
 	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		version, err := strconv.Atoi(scanner.Text())
+	for scanner.Scan() && len(values) < 2 {
+		value, err := strconv.Atoi(scanner.Text())
 		if err != nil {
-			return 0, err
+			return 0, 0, err
 		}
-		return version, nil
+		values = append(values, value)
 	}
 
-	return 0, fmt.Errorf("No data in index mpd library state file")
+	switch len(values) {
+	case 0:
+		return 0, 0, fmt.Errorf("No data in index mpd library state file")
+	case 1:
+		return values[0], 0, nil
+	default:
+		return values[0], values[1], nil
+	}
 }
 
 // Version returns the index version. It should correspond to the MPD library version.
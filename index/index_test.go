@@ -0,0 +1,61 @@
+package index_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ambientsound/pms/index"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test that opening an index which is already open gives up after the timeout.
+func TestIndexLocked(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pms-index")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	first, err := index.New(dir, 0)
+	require.Nil(t, err)
+
+	_, err = index.New(dir, 100*time.Millisecond)
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "locked by a running pms")
+	}
+
+	// The index can be opened once it is released.
+	require.Nil(t, first.Close())
+	second, err := index.New(dir, time.Second)
+	if assert.Nil(t, err) {
+		second.Close()
+	}
+}
+
+// Test that the library version is kept when the index is reopened, and that
+// indexes written with an older schema version are rebuilt.
+func TestIndexSchemaVersion(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pms-index")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	i, err := index.New(dir, 0)
+	require.Nil(t, err)
+	require.Nil(t, i.SetVersion(1234))
+	require.Nil(t, i.Close())
+
+	i, err = index.New(dir, 0)
+	require.Nil(t, err)
+	assert.Equal(t, 1234, i.Version())
+	require.Nil(t, i.Close())
+
+	// State files without a schema version are from before the index schema
+	// was versioned.
+	require.Nil(t, ioutil.WriteFile(filepath.Join(dir, "state"), []byte("1234\n"), 0644))
+	i, err = index.New(dir, 0)
+	require.Nil(t, err)
+	assert.Equal(t, 0, i.Version())
+	require.Nil(t, i.Close())
+}
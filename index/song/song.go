@@ -6,25 +6,43 @@ import (
 	"github.com/ambientsound/pms/song"
 )
 
-// Song is a Bleve document representing a song.Song object.
+// Song is a Bleve document representing a song.Song object. Tags that may
+// have several values, such as artist and genre, are indexed with each value
+// separately.
 type Song struct {
-	Album       string
-	Albumartist string
-	Artist      string
+	Album       []string
+	Albumartist []string
+	Artist      []string
 	File        string
-	Genre       string
-	Title       string
+	Genre       []string
+	Title       []string
 	Year        string
 }
 
 // New generates a indexable Song document, containing some fields from the song.Song type.
 func New(s *song.Song) (is Song) {
-	is.Album = s.StringTags["album"]
-	is.Albumartist = s.StringTags["albumartist"]
-	is.Artist = s.StringTags["artist"]
+	is.Album = s.Values("album")
+	is.Albumartist = s.Values("albumartist")
+	is.Artist = s.Values("artist")
 	is.File = s.StringTags["file"]
-	is.Genre = s.StringTags["genre"]
-	is.Title = s.StringTags["title"]
+	is.Genre = s.Values("genre")
+	is.Title = s.Values("title")
 	is.Year = s.StringTags["year"]
 	return
 }
+
+// indexedTags are the song tags which are stored in the search index.
+var indexedTags = map[string]bool{
+	"album":       true,
+	"albumartist": true,
+	"artist":      true,
+	"file":        true,
+	"genre":       true,
+	"title":       true,
+	"year":        true,
+}
+
+// Indexed returns true if the given tag is stored in the search index.
+func Indexed(tag string) bool {
+	return indexedTags[tag]
+}
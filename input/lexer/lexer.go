@@ -114,6 +114,39 @@ func (s *Scanner) ScanIgnoreWhitespace() (tok int, lit string) {
 	return
 }
 
+// ScanRest returns the rest of the input as-is, without interpreting any
+// quotes, escapes, or other special characters.
+func (s *Scanner) ScanRest() string {
+	var buf bytes.Buffer
+	for {
+		ch := s.read()
+		if ch == eof {
+			break
+		}
+		buf.WriteRune(ch)
+	}
+	return buf.String()
+}
+
+// ScanUntil returns the input up to, but not including, the first rune for
+// which stop returns true. Quotes, escapes, and other special characters are
+// not interpreted.
+func (s *Scanner) ScanUntil(stop func(rune) bool) string {
+	var buf bytes.Buffer
+	for {
+		ch := s.read()
+		if ch == eof {
+			break
+		}
+		if stop(ch) {
+			s.unread()
+			break
+		}
+		buf.WriteRune(ch)
+	}
+	return buf.String()
+}
+
 // scanWhitespace consumes the current rune and all contiguous whitespace.
 func (s *Scanner) scanWhitespace() string {
 	var buf bytes.Buffer
@@ -107,3 +107,30 @@ func TestLexer(t *testing.T) {
 		}
 	}
 }
+
+// Test that the rest of the input is returned without interpreting escapes
+// or other special characters.
+func TestScanRest(t *testing.T) {
+	reader := strings.NewReader(`define ext = file:regex(\.(\w+)$) # "x";`)
+	scanner := lexer.NewScanner(reader)
+
+	class, str := scanner.Scan()
+	assert.Equal(t, lexer.TokenIdentifier, class)
+	assert.Equal(t, `define`, str)
+
+	assert.Equal(t, ` ext = file:regex(\.(\w+)$) # "x";`, scanner.ScanRest())
+	assert.Equal(t, ``, scanner.ScanRest())
+}
+
+// Test that input is returned as-is up to the stop rune, which is left for
+// the next scan.
+func TestScanUntil(t *testing.T) {
+	reader := strings.NewReader(`[=>-]#\x} end`)
+	scanner := lexer.NewScanner(reader)
+
+	assert.Equal(t, `[=>-]#\x`, scanner.ScanUntil(func(r rune) bool { return r == '}' }))
+	class, str := scanner.Scan()
+	assert.Equal(t, lexer.TokenClose, class)
+	assert.Equal(t, `}`, str)
+	assert.Equal(t, ` end`, scanner.ScanUntil(func(r rune) bool { return r == '}' }))
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"path"
@@ -8,6 +9,7 @@ import (
 
 	"github.com/ambientsound/pms/console"
 	"github.com/ambientsound/pms/pms"
+	"github.com/ambientsound/pms/remote"
 	"github.com/ambientsound/pms/version"
 	"github.com/ambientsound/pms/xdg"
 
@@ -17,11 +19,14 @@ import (
 var buildVersion = "undefined"
 
 type cliOptions struct {
-	Version     bool   `short:"v" long:"version" description:"Print program version"`
-	Debug       string `short:"d" long:"debug" description:"Write debugging info to file"`
-	MpdHost     string `long:"host" description:"MPD host" default-mask:"MPD_HOST environment variable or localhost"`
-	MpdPort     string `long:"port" description:"MPD port" default-mask:"MPD_PORT environment variable or 6600"`
-	MpdPassword string `long:"password" description:"MPD password"`
+	Version     bool     `short:"v" long:"version" description:"Print program version"`
+	Debug       string   `short:"d" long:"debug" description:"Write debugging info to file"`
+	MpdHost     string   `long:"host" description:"MPD host" default-mask:"MPD_HOST environment variable or localhost"`
+	MpdPort     string   `long:"port" description:"MPD port" default-mask:"MPD_PORT environment variable or 6600"`
+	MpdPassword string   `long:"password" description:"MPD password"`
+	Batch       []string `short:"c" long:"batch" description:"Run a command without starting the user interface; can be given multiple times"`
+	Remote      string   `long:"remote" description:"Run a command in an already running PMS instance, and print the result as JSON"`
+	Socket      string   `long:"socket" description:"Control socket used by --remote" default-mask:"most recently started PMS instance"`
 }
 
 // mpdEnvironmentVariables reads the host, port, and password parameters to MPD
@@ -65,7 +70,6 @@ func main() {
 	var opts cliOptions
 
 	version.SetVersion(buildVersion)
-	fmt.Printf("%s %s\n", version.LongName(), version.Version())
 
 	remainder, err := flags.Parse(&opts)
 	if err != nil {
@@ -77,6 +81,18 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Remote commands are sent to another PMS instance, and the response is
+	// written to stdout without any other output.
+	if len(opts.Remote) > 0 {
+		os.Exit(runRemote(opts.Socket, opts.Remote))
+	}
+
+	// In batch mode, stdout is reserved for command output.
+	batch := len(opts.Batch) > 0
+	if !batch {
+		fmt.Printf("%s %s\n", version.LongName(), version.Version())
+	}
+
 	if len(opts.Debug) > 0 {
 		err := console.Open(opts.Debug)
 		if err != nil {
@@ -91,16 +107,17 @@ func main() {
 
 	console.Log("Starting Practical Music Search.")
 
-	p, err := pms.New()
+	var p *pms.PMS
+	if batch {
+		p, err = pms.NewBatch()
+	} else {
+		p, err = pms.New()
+	}
 	if err != nil {
 		fmt.Printf("Error starting up: %s", err)
 		os.Exit(1)
 	}
 
-	defer func() {
-		p.QuitSignal <- 0
-	}()
-
 	// Source default configuration.
 	p.Message("Applying default configuration.")
 	if err := p.SourceDefaultConfig(); err != nil {
@@ -125,8 +142,21 @@ func main() {
 	// Set up the self-healing connection.
 	p.Connection = pms.NewConnection(p.EventMessage)
 	p.Connection.Open(host, port, password)
+
+	if batch {
+		if err := p.RunBatch(opts.Batch, os.Stdout); err != nil {
+			os.Exit(1)
+		}
+		console.Log("Batch finished.")
+		os.Exit(0)
+	}
+
 	go p.Connection.Run()
 
+	defer func() {
+		p.QuitSignal <- 0
+	}()
+
 	// Every second counts
 	go p.RunTicker()
 
@@ -135,3 +165,30 @@ func main() {
 
 	console.Log("Exiting normally.")
 }
+
+// runRemote sends a command line to a running PMS instance, prints the JSON
+// response, and returns the program exit code.
+func runRemote(socket, line string) int {
+	var err error
+
+	if len(socket) == 0 {
+		socket, err = remote.Find()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %s\n", err)
+			return 1
+		}
+	}
+
+	response, err := remote.Send(socket, line)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %s\n", err)
+		return 1
+	}
+
+	err = json.NewEncoder(os.Stdout).Encode(response)
+	if err != nil || !response.Success {
+		return 1
+	}
+
+	return 0
+}
@@ -0,0 +1,65 @@
+package message
+
+import (
+	"sync"
+)
+
+// Buffer is a ring buffer that holds the most recent messages.
+type Buffer struct {
+	messages []Message
+	next     int
+	full     bool
+	mutex    sync.Mutex
+}
+
+// NewBuffer returns Buffer, which holds up to size messages.
+func NewBuffer(size int) *Buffer {
+	return &Buffer{
+		messages: make([]Message, size),
+	}
+}
+
+// Add adds a message to the buffer, overwriting the oldest message if the
+// buffer is full.
+func (b *Buffer) Add(msg Message) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if len(b.messages) == 0 {
+		return
+	}
+
+	b.messages[b.next] = msg
+	b.next = (b.next + 1) % len(b.messages)
+	if b.next == 0 {
+		b.full = true
+	}
+}
+
+// Messages returns all messages in the buffer, oldest first.
+func (b *Buffer) Messages() []Message {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if !b.full {
+		messages := make([]Message, b.next)
+		copy(messages, b.messages[:b.next])
+		return messages
+	}
+
+	messages := make([]Message, 0, len(b.messages))
+	messages = append(messages, b.messages[b.next:]...)
+	messages = append(messages, b.messages[:b.next]...)
+	return messages
+}
+
+// Len returns the number of messages in the buffer.
+func (b *Buffer) Len() int {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.full {
+		return len(b.messages)
+	}
+	return b.next
+}
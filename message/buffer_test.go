@@ -0,0 +1,51 @@
+package message_test
+
+import (
+	"testing"
+
+	"github.com/ambientsound/pms/message"
+	"github.com/stretchr/testify/assert"
+)
+
+var bufferTests = []struct {
+	size     int
+	add      int
+	expected []string
+}{
+	{3, 0, []string{}},
+	{3, 2, []string{"0", "1"}},
+	{3, 3, []string{"0", "1", "2"}},
+	{3, 5, []string{"2", "3", "4"}},
+	{3, 6, []string{"3", "4", "5"}},
+	{0, 2, []string{}},
+}
+
+// Test that the message buffer keeps only the most recent messages, in order.
+func TestBuffer(t *testing.T) {
+	for n, test := range bufferTests {
+		t.Logf("### Test %d: size %d, adding %d messages", n+1, test.size, test.add)
+
+		buffer := message.NewBuffer(test.size)
+		for i := 0; i < test.add; i++ {
+			buffer.Add(message.Format("%d", i))
+		}
+
+		texts := make([]string, 0)
+		for _, msg := range buffer.Messages() {
+			texts = append(texts, msg.Text)
+		}
+
+		assert.Equal(t, test.expected, texts)
+		assert.Equal(t, len(test.expected), buffer.Len())
+	}
+}
+
+func TestParseSeverity(t *testing.T) {
+	for _, name := range message.SeverityNames() {
+		severity, err := message.ParseSeverity(name)
+		assert.Nil(t, err)
+		assert.Equal(t, name, message.Message{Severity: severity}.SeverityName())
+	}
+	_, err := message.ParseSeverity("foo")
+	assert.NotNil(t, err)
+}
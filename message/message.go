@@ -3,6 +3,7 @@ package message
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/ambientsound/pms/console"
 )
@@ -13,6 +14,7 @@ type Message struct {
 	Text     string
 	Severity int
 	Type     int
+	Time     time.Time
 }
 
 // Message severities. INFO messages and above will end up in the statusbar.
@@ -22,6 +24,13 @@ const (
 	Error
 )
 
+// severityNames maps message severities to their textual representation.
+var severityNames = map[int]string{
+	Debug: "debug",
+	Info:  "info",
+	Error: "error",
+}
+
 // Message types.
 const (
 	Normal = iota
@@ -34,6 +43,7 @@ func format(severity int, t int, format string, a ...interface{}) Message {
 		Text:     fmt.Sprintf(format, a...),
 		Severity: severity,
 		Type:     t,
+		Time:     time.Now(),
 	}
 }
 
@@ -52,6 +62,27 @@ func Sequencef(fmt string, a ...interface{}) Message {
 	return format(Info, SequenceText, fmt, a...)
 }
 
+// SeverityName returns the lowercase name of the message severity.
+func (msg Message) SeverityName() string {
+	return severityNames[msg.Severity]
+}
+
+// ParseSeverity returns the message severity with the given name.
+func ParseSeverity(name string) (int, error) {
+	for severity, severityName := range severityNames {
+		if name == severityName {
+			return severity, nil
+		}
+	}
+	return 0, fmt.Errorf("Unknown message severity '%s'", name)
+}
+
+// SeverityNames returns the names of all message severities, from the least
+// to the most severe.
+func SeverityNames() []string {
+	return []string{severityNames[Debug], severityNames[Info], severityNames[Error]}
+}
+
 // Log prints a message to the debug log.
 func Log(msg Message) {
 	if msg.Type != Normal {
@@ -11,6 +11,8 @@ type PlayerStatus struct {
 	ElapsedPercentage float64
 	Err               string
 	MixRampDB         float64
+	NextSong          int
+	NextSongID        int
 	Playlist          int
 	PlaylistLength    int
 	Random            bool
@@ -0,0 +1,190 @@
+package mpd
+
+import (
+	"fmt"
+	"net/textproto"
+	"strings"
+)
+
+// RawClient is a minimal MPD protocol client, used for commands that are not
+// supported by the regular MPD client library, such as relative queue
+// positions. Each RawClient is a separate connection to MPD.
+type RawClient struct {
+	text    *textproto.Conn
+	version Version
+}
+
+// DialRaw connects to MPD, reads the server version from the greeting, and
+// authenticates using the password if it is not empty.
+func DialRaw(network, addr, password string) (*RawClient, error) {
+	text, err := textproto.Dial(network, addr)
+	if err != nil {
+		return nil, err
+	}
+
+	line, err := text.ReadLine()
+	if err != nil {
+		text.Close()
+		return nil, err
+	}
+
+	version, err := ParseGreeting(line)
+	if err != nil {
+		text.Close()
+		return nil, err
+	}
+
+	c := &RawClient{
+		text:    text,
+		version: version,
+	}
+
+	if len(password) > 0 {
+		if err = c.Command("password " + Quote(password)); err != nil {
+			c.Close()
+			return nil, err
+		}
+	}
+
+	return c, nil
+}
+
+// Version returns the protocol version of the MPD server.
+func (c *RawClient) Version() Version {
+	return c.version
+}
+
+// Close closes the connection to MPD.
+func (c *RawClient) Close() error {
+	return c.text.Close()
+}
+
+// Ping checks that the connection to MPD is still alive.
+func (c *RawClient) Ping() error {
+	return c.Command("ping")
+}
+
+// Command sends a single command to MPD, and waits for it to complete. Any
+// response data is discarded.
+func (c *RawClient) Command(command string) error {
+	if err := c.writeLines(command); err != nil {
+		return err
+	}
+	return c.readOK()
+}
+
+// CommandList sends a list of commands to MPD, which are executed atomically.
+// If any of the commands fail, the rest of the list is not executed.
+func (c *RawClient) CommandList(commands []string) error {
+	lines := make([]string, 0, len(commands)+2)
+	lines = append(lines, "command_list_begin")
+	lines = append(lines, commands...)
+	lines = append(lines, "command_list_end")
+	if err := c.writeLines(lines...); err != nil {
+		return err
+	}
+	return c.readOK()
+}
+
+// MultiAttrs holds the attributes of an entry in an MPD response. Unlike the
+// regular MPD client library, every value is kept when a key appears several
+// times, such as for songs with multiple artists.
+type MultiAttrs map[string][]string
+
+// ListAllInfo returns all songs in the MPD database below the given URI.
+func (c *RawClient) ListAllInfo(uri string) ([]MultiAttrs, error) {
+	return c.songList("listallinfo " + Quote(uri))
+}
+
+// PlChanges returns all songs in the queue that have changed since the given
+// queue version.
+func (c *RawClient) PlChanges(version int) ([]MultiAttrs, error) {
+	return c.songList(fmt.Sprintf("plchanges %d", version))
+}
+
+// CurrentSong returns the current song, or empty attributes if there is no
+// current song.
+func (c *RawClient) CurrentSong() (MultiAttrs, error) {
+	songs, err := c.songList("currentsong")
+	if err != nil || len(songs) == 0 {
+		return MultiAttrs{}, err
+	}
+	return songs[0], nil
+}
+
+// songList sends a command to MPD, and reads the songs in the response. Each
+// song starts with a file attribute. Directories and playlists are skipped.
+func (c *RawClient) songList(command string) ([]MultiAttrs, error) {
+	if err := c.writeLines(command); err != nil {
+		return nil, err
+	}
+
+	songs := make([]MultiAttrs, 0)
+	inSong := false
+
+	for {
+		line, err := c.text.ReadLine()
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case line == "OK":
+			return songs, nil
+		case strings.HasPrefix(line, "ACK "):
+			return nil, fmt.Errorf("MPD error: %s", line[4:])
+		}
+
+		i := strings.Index(line, ": ")
+		if i < 0 {
+			return nil, fmt.Errorf("Cannot parse MPD response: %s", line)
+		}
+		key, value := line[:i], line[i+2:]
+
+		switch key {
+		case "file":
+			songs = append(songs, MultiAttrs{})
+			inSong = true
+		case "directory", "playlist":
+			inSong = false
+		}
+
+		if inSong {
+			song := songs[len(songs)-1]
+			song[key] = append(song[key], value)
+		}
+	}
+}
+
+// writeLines writes lines to MPD. MPD requires lines to be terminated by a
+// single newline, so textproto's PrintfLine can not be used.
+func (c *RawClient) writeLines(lines ...string) error {
+	for _, line := range lines {
+		if _, err := c.text.W.WriteString(line + "\n"); err != nil {
+			return err
+		}
+	}
+	return c.text.W.Flush()
+}
+
+// readOK reads response lines until MPD signals success or failure.
+func (c *RawClient) readOK() error {
+	for {
+		line, err := c.text.ReadLine()
+		if err != nil {
+			return err
+		}
+		switch {
+		case line == "OK":
+			return nil
+		case strings.HasPrefix(line, "ACK "):
+			return fmt.Errorf("MPD error: %s", line[4:])
+		}
+	}
+}
+
+// Quote quotes a string as a single MPD command argument.
+func Quote(s string) string {
+	s = strings.Replace(s, `\`, `\\`, -1)
+	s = strings.Replace(s, `"`, `\"`, -1)
+	return `"` + s + `"`
+}
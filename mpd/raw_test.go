@@ -0,0 +1,152 @@
+package mpd_test
+
+import (
+	"bufio"
+	"net"
+	"testing"
+
+	"github.com/ambientsound/pms/mpd"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeServer accepts a single connection, sends a greeting, and records the
+// received lines. Each command or command list is answered with the given
+// response.
+func fakeServer(t *testing.T, response string) (net.Listener, chan []string) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("unable to listen on loopback: %s", err)
+	}
+
+	received := make(chan []string, 1)
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		lines := make([]string, 0)
+		inList := false
+		conn.Write([]byte("OK MPD 0.23.5\n"))
+		scanner := bufio.NewScanner(conn)
+		for scanner.Scan() {
+			line := scanner.Text()
+			lines = append(lines, line)
+			switch {
+			case line == "command_list_begin":
+				inList = true
+			case line == "command_list_end", !inList:
+				inList = false
+				conn.Write([]byte(response))
+			}
+		}
+		received <- lines
+	}()
+
+	return listener, received
+}
+
+func TestRawClientCommandList(t *testing.T) {
+	listener, received := fakeServer(t, "Id: 1\nId: 2\nOK\n")
+	defer listener.Close()
+
+	client, err := mpd.DialRaw("tcp", listener.Addr().String(), "")
+	assert.Nil(t, err)
+	assert.Equal(t, mpd.Version{0, 23, 5}, client.Version())
+
+	err = client.CommandList([]string{
+		`addid "foo" +0`,
+		`addid "bar" +1`,
+	})
+	assert.Nil(t, err)
+	client.Close()
+
+	assert.Equal(t, []string{
+		"command_list_begin",
+		`addid "foo" +0`,
+		`addid "bar" +1`,
+		"command_list_end",
+	}, <-received)
+}
+
+func TestRawClientError(t *testing.T) {
+	listener, _ := fakeServer(t, "ACK [2@0] {addid} Bad song index\n")
+	defer listener.Close()
+
+	client, err := mpd.DialRaw("tcp", listener.Addr().String(), "")
+	assert.Nil(t, err)
+	defer client.Close()
+
+	err = client.CommandList([]string{`addid "foo" +0`})
+	assert.NotNil(t, err)
+}
+
+// Test that songs with several values for the same tag keep all values, and
+// that directories are skipped.
+func TestRawClientListAllInfo(t *testing.T) {
+	listener, received := fakeServer(t, `directory: foo
+Last-Modified: 2020-01-01T00:00:00Z
+file: foo/bar.flac
+Artist: Alice
+Artist: Bob
+Title: Duet
+file: foo/baz.flac
+Artist: Alice
+playlist: foo/list.m3u
+Last-Modified: 2020-01-01T00:00:00Z
+OK
+`)
+	defer listener.Close()
+
+	client, err := mpd.DialRaw("tcp", listener.Addr().String(), "")
+	assert.Nil(t, err)
+
+	songs, err := client.ListAllInfo("foo")
+	assert.Nil(t, err)
+	client.Close()
+
+	assert.Equal(t, []mpd.MultiAttrs{
+		{
+			"file":   {"foo/bar.flac"},
+			"Artist": {"Alice", "Bob"},
+			"Title":  {"Duet"},
+		},
+		{
+			"file":   {"foo/baz.flac"},
+			"Artist": {"Alice"},
+		},
+	}, songs)
+
+	assert.Equal(t, []string{`listallinfo "foo"`}, <-received)
+}
+
+func TestRawClientCurrentSong(t *testing.T) {
+	listener, _ := fakeServer(t, "OK\n")
+	defer listener.Close()
+
+	client, err := mpd.DialRaw("tcp", listener.Addr().String(), "")
+	assert.Nil(t, err)
+	defer client.Close()
+
+	song, err := client.CurrentSong()
+	assert.Nil(t, err)
+	assert.Equal(t, mpd.MultiAttrs{}, song)
+}
+
+// Test that the connection can be pinged, and that a closed connection
+// results in an error.
+func TestRawClientPing(t *testing.T) {
+	listener, received := fakeServer(t, "OK\n")
+	defer listener.Close()
+
+	client, err := mpd.DialRaw("tcp", listener.Addr().String(), "")
+	assert.Nil(t, err)
+
+	assert.Nil(t, client.Ping())
+	client.Close()
+	assert.NotNil(t, client.Ping())
+
+	assert.Equal(t, []string{"ping"}, <-received)
+}
@@ -0,0 +1,60 @@
+package mpd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Version is the protocol version of an MPD server, as announced in the
+// greeting sent by the server when a client connects.
+type Version struct {
+	Major int
+	Minor int
+	Patch int
+}
+
+// greeting is the prefix of the first line sent by MPD to connecting clients.
+const greeting = "OK MPD "
+
+// ParseGreeting parses an MPD greeting line, such as "OK MPD 0.23.5", and
+// returns the protocol version.
+func ParseGreeting(line string) (Version, error) {
+	if !strings.HasPrefix(line, greeting) {
+		return Version{}, fmt.Errorf("Unexpected MPD greeting '%s'", line)
+	}
+	return ParseVersion(line[len(greeting):])
+}
+
+// ParseVersion parses a version string such as "0.23.5". The patch number is
+// optional.
+func ParseVersion(s string) (Version, error) {
+	var err error
+	v := Version{}
+	parts := strings.Split(strings.TrimSpace(s), ".")
+	if len(parts) < 2 || len(parts) > 3 {
+		return v, fmt.Errorf("Invalid MPD version '%s'", s)
+	}
+	nums := []*int{&v.Major, &v.Minor, &v.Patch}
+	for i := range parts {
+		*nums[i], err = strconv.Atoi(parts[i])
+		if err != nil {
+			return Version{}, fmt.Errorf("Invalid MPD version '%s'", s)
+		}
+	}
+	return v, nil
+}
+
+// AtLeast returns true if the version is equal to or newer than the given
+// major and minor version.
+func (v Version) AtLeast(major, minor int) bool {
+	if v.Major != major {
+		return v.Major > major
+	}
+	return v.Minor >= minor
+}
+
+// String returns the version formatted as "major.minor.patch".
+func (v Version) String() string {
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
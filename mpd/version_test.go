@@ -0,0 +1,49 @@
+package mpd_test
+
+import (
+	"testing"
+
+	"github.com/ambientsound/pms/mpd"
+	"github.com/stretchr/testify/assert"
+)
+
+var greetingTests = []struct {
+	input   string
+	version mpd.Version
+	success bool
+}{
+	{"OK MPD 0.23.5", mpd.Version{0, 23, 5}, true},
+	{"OK MPD 0.19.0", mpd.Version{0, 19, 0}, true},
+	{"OK MPD 1.0", mpd.Version{1, 0, 0}, true},
+	{"OK MPD", mpd.Version{}, false},
+	{"OK MPD 0", mpd.Version{}, false},
+	{"OK MPD 0.x.1", mpd.Version{}, false},
+	{"OK FOO 0.23.5", mpd.Version{}, false},
+}
+
+func TestParseGreeting(t *testing.T) {
+	for _, test := range greetingTests {
+		version, err := mpd.ParseGreeting(test.input)
+		if test.success {
+			assert.Nil(t, err, test.input)
+		} else {
+			assert.NotNil(t, err, test.input)
+		}
+		assert.Equal(t, test.version, version, test.input)
+	}
+}
+
+func TestVersionAtLeast(t *testing.T) {
+	v := mpd.Version{0, 23, 5}
+	assert.True(t, v.AtLeast(0, 23))
+	assert.True(t, v.AtLeast(0, 21))
+	assert.False(t, v.AtLeast(0, 24))
+	assert.False(t, v.AtLeast(1, 0))
+	assert.True(t, mpd.Version{1, 0, 0}.AtLeast(0, 23))
+	assert.Equal(t, "0.23.5", v.String())
+}
+
+func TestQuote(t *testing.T) {
+	assert.Equal(t, `"foo bar"`, mpd.Quote(`foo bar`))
+	assert.Equal(t, `"a \"b\" \\c"`, mpd.Quote(`a "b" \c`))
+}
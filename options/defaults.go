@@ -5,7 +5,18 @@ package options
 func (o *Options) AddDefaultOptions() {
 	o.Add(NewBoolOption("center"))
 	o.Add(NewStringOption("columns"))
+	o.Add(NewIntOption("duplicatetime"))
+	o.Add(NewStringOption("historycolumns"))
+	o.Add(NewIntOption("historythreshold"))
+	o.Add(NewStringOption("librarycolumns"))
+	o.Add(NewBoolOption("mouse"))
+	o.Add(NewStringOption("musicdir"))
+	o.Add(NewBoolOption("remote"))
+	o.Add(NewStringOption("queuecolumns"))
 	o.Add(NewStringOption("sort"))
+	o.Add(NewStringOption("statusbar"))
+	o.Add(NewStringOption("statusbarmessages"))
+	o.Add(NewStringOption("tagseparator"))
 	o.Add(NewStringOption("topbar"))
 }
 
@@ -13,9 +24,16 @@ func (o *Options) AddDefaultOptions() {
 const Defaults string = `
 # Global options
 set nocenter
+set mouse
+set noremote
 set columns=artist,track,title,album,year,time
+set duplicatetime=0
+set historythreshold=30
 set sort=file,track,disc,album,year,albumartistsort
-set topbar="|$shortname $version||;${tag|artist} - ${tag|title}||${tag|album}, ${tag|year};$volume $mode $elapsed ${state} $time;|[${list|index}/${list|total}] ${list|title}||;;"
+set statusbarmessages=info,error
+set tagseparator="; "
+set statusbar="$message|${if selection}${selection} (${selection|duration})    ${endif}${if sequence}${sequence}    ${endif}$readout"
+set topbar="|$shortname $version||;${if tag|file}${tag|artist ?: tag|albumartist} - ${tag|title ?: tag|file}${endif}||${if tag|album}${tag|album}${if tag|year}, ${tag|year}${endif}${endif};$volume $mode $elapsed ${state} $time;|[${list|index}/${list|total}] ${list|title}||;;"
 
 # Song tag styles
 style album teal
@@ -27,6 +45,17 @@ style disc darkgreen
 style track green
 style year green
 style originalyear darkgreen
+style played darkgray
+style prio olive
+style timestamp darkgray
+style severity teal
+style name white bold
+style type teal
+style songs darkblue
+style duration darkmagenta
+style section green
+style count darkblue
+style message default
 
 # Tracklist styles
 style allTagsMissing red
@@ -34,15 +63,25 @@ style currentSong black yellow
 style cursor black white
 style header green bold
 style mostTagsMissing red
+style prioritySong olive
 style selection white blue
 
 # Topbar styles
+style audio darkcyan
+style bitrate darkcyan
+style clock default
 style elapsedTime green
 style elapsedPercentage green
+style historyToday green
+style historyTotal darkgreen
 style listIndex darkblue
 style listTitle blue bold
 style listTotal darkblue
 style mute red
+style progressEmpty darkgray
+style progressFilled green
+style queueDuration darkblue
+style queueLength darkblue
 style shortName bold
 style state default
 style switches teal
@@ -56,6 +95,8 @@ style commandText default
 style errorText white red bold
 style readout default
 style searchText white bold
+style selectionCount teal
+style selectionDuration teal
 style sequenceText teal
 style statusbar default
 style visualText teal
@@ -115,6 +156,10 @@ bind S single
 bind <C-c> quit
 bind <C-l> redraw
 bind <C-s> sort
+bind <C-Up> move up
+bind K move up
+bind <C-Down> move down
+bind J move down
 bind i print file
 bind gt list next
 bind gT list previous
@@ -122,11 +167,14 @@ bind t list next
 bind T list previous
 bind <C-w>d list duplicate
 bind <C-g> list remove
+bind gl lists
+bind <C-w>o list open
 bind <C-j> isolate artist
 bind <C-t> isolate albumartist album
 bind & select nearby albumartist album
 bind m select toggle
 bind a add
+bind A add next
 bind <Delete> cut
 bind x cut
 bind y yank
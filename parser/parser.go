@@ -77,6 +77,32 @@ func (p *Parser) ScanIgnoreWhitespace() (tok int, lit string) {
 	return
 }
 
+// ScanRest returns the rest of the input as-is, starting with any token that
+// has been unscanned.
+func (p *Parser) ScanRest() string {
+	rest := ""
+	if p.buf.n != 0 {
+		p.buf.n = 0
+		rest = p.buf.Lit
+	}
+	rest += p.S.ScanRest()
+	p.scanned = append(p.scanned, Token{lexer.TokenIdentifier, rest})
+	return rest
+}
+
+// ScanUntil returns the input as-is up to the first rune for which stop
+// returns true, starting with any token that has been unscanned.
+func (p *Parser) ScanUntil(stop func(rune) bool) string {
+	rest := ""
+	if p.buf.n != 0 {
+		p.buf.n = 0
+		rest = p.buf.Lit
+	}
+	rest += p.S.ScanUntil(stop)
+	p.scanned = append(p.scanned, Token{lexer.TokenIdentifier, rest})
+	return rest
+}
+
 // Unscan pushes the previously read token back onto the buffer.
 func (p *Parser) Unscan() { p.buf.n = 1 }
 
@@ -0,0 +1,147 @@
+package playlist
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/url"
+	"sort"
+	"strconv"
+
+	"github.com/ambientsound/pms/song"
+)
+
+// exporters maps each export format to the function that writes it.
+var exporters = map[string]func(w io.Writer, songs []*song.Song, columns []string) error{
+	"csv":  exportCSV,
+	"json": exportJSON,
+	"m3u":  exportM3U,
+	"m3u8": exportM3U,
+	"xspf": exportXSPF,
+}
+
+// ExportFormats returns the names of all formats supported by Export.
+func ExportFormats() []string {
+	formats := make([]string, 0, len(exporters))
+	for format := range exporters {
+		formats = append(formats, format)
+	}
+	sort.Strings(formats)
+	return formats
+}
+
+// CanExport returns true if Export supports the given format.
+func CanExport(format string) bool {
+	_, ok := exporters[format]
+	return ok
+}
+
+// Export writes songs to w using the given format. The columns are used as
+// the fields of the CSV format, and are ignored by the other formats.
+func Export(w io.Writer, format string, songs []*song.Song, columns []string) error {
+	exporter, ok := exporters[format]
+	if !ok {
+		return fmt.Errorf("Unknown export format '%s'", format)
+	}
+	return exporter(w, songs, columns)
+}
+
+// exportM3U writes an extended M3U playlist. M3U and M3U8 files are both
+// written using UTF-8.
+func exportM3U(w io.Writer, songs []*song.Song, columns []string) error {
+	if _, err := fmt.Fprintln(w, "#EXTM3U"); err != nil {
+		return err
+	}
+	for _, s := range songs {
+		_, err := fmt.Fprintf(w, "#EXTINF:%d,%s\n%s\n", duration(s), title(s), s.StringTags["file"])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// xspfPlaylist is the XML document of an XSPF playlist.
+type xspfPlaylist struct {
+	XMLName xml.Name    `xml:"http://xspf.org/ns/0/ playlist"`
+	Version int         `xml:"version,attr"`
+	Tracks  []xspfTrack `xml:"trackList>track"`
+}
+
+// xspfTrack is a single track of an XSPF playlist.
+type xspfTrack struct {
+	Location string `xml:"location"`
+	Title    string `xml:"title,omitempty"`
+	Creator  string `xml:"creator,omitempty"`
+	Album    string `xml:"album,omitempty"`
+	TrackNum int    `xml:"trackNum,omitempty"`
+	Duration int    `xml:"duration,omitempty"`
+}
+
+// exportXSPF writes an XSPF playlist. Song files are written as relative URIs.
+func exportXSPF(w io.Writer, songs []*song.Song, columns []string) error {
+	playlist := xspfPlaylist{
+		Version: 1,
+		Tracks:  make([]xspfTrack, len(songs)),
+	}
+
+	for i, s := range songs {
+		location := url.URL{Path: s.StringTags["file"]}
+		track := xspfTrack{
+			Location: location.String(),
+			Title:    s.StringTags["title"],
+			Creator:  s.StringTags["artist"],
+			Album:    s.StringTags["album"],
+		}
+		track.TrackNum, _ = strconv.Atoi(s.StringTags["track"])
+		if secs := duration(s); secs > 0 {
+			track.Duration = secs * 1000
+		}
+		playlist.Tracks[i] = track
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(playlist); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// exportJSON writes a JSON array with all tags of each song.
+func exportJSON(w io.Writer, songs []*song.Song, columns []string) error {
+	tags := make([]song.StringTaglist, len(songs))
+	for i, s := range songs {
+		tags[i] = s.StringTags
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(tags)
+}
+
+// exportCSV writes a header line with the column names, followed by the
+// column values of each song.
+func exportCSV(w io.Writer, songs []*song.Song, columns []string) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(columns); err != nil {
+		return err
+	}
+	for _, s := range songs {
+		record := make([]string, len(columns))
+		for i, tag := range columns {
+			record[i] = s.StringTags[tag]
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,93 @@
+package playlist_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ambientsound/gompd/mpd"
+	"github.com/ambientsound/pms/playlist"
+	"github.com/ambientsound/pms/song"
+	"github.com/stretchr/testify/assert"
+)
+
+func exportSongs() []*song.Song {
+	a := song.New()
+	a.SetTags(mpd.Attrs{
+		"file":   "foo/01 bar & baz.flac",
+		"artist": "Foo",
+		"title":  "Bar & Baz",
+		"album":  "Qux",
+		"track":  "1",
+		"time":   "185",
+	})
+	b := song.New()
+	b.SetTags(mpd.Attrs{
+		"file": "untitled.mp3",
+	})
+	return []*song.Song{a, b}
+}
+
+var exportTests = []struct {
+	format  string
+	success bool
+	output  string
+}{
+	{"m3u", true, `#EXTM3U
+#EXTINF:185,Foo - Bar & Baz
+foo/01 bar & baz.flac
+#EXTINF:-1,untitled.mp3
+untitled.mp3
+`},
+	{"xspf", true, `<?xml version="1.0" encoding="UTF-8"?>
+<playlist xmlns="http://xspf.org/ns/0/" version="1">
+  <trackList>
+    <track>
+      <location>foo/01%20bar%20&amp;%20baz.flac</location>
+      <title>Bar &amp; Baz</title>
+      <creator>Foo</creator>
+      <album>Qux</album>
+      <trackNum>1</trackNum>
+      <duration>185000</duration>
+    </track>
+    <track>
+      <location>untitled.mp3</location>
+    </track>
+  </trackList>
+</playlist>
+`},
+	{"csv", true, `artist,title,time
+Foo,Bar & Baz,185
+,,
+`},
+	{"json", true, `[
+  {
+    "album": "Qux",
+    "artist": "Foo",
+    "file": "foo/01 bar & baz.flac",
+    "time": "185",
+    "title": "Bar & Baz",
+    "track": "1"
+  },
+  {
+    "file": "untitled.mp3"
+  }
+]
+`},
+	{"pls", false, ``},
+}
+
+func TestExport(t *testing.T) {
+	songs := exportSongs()
+	columns := []string{"artist", "title", "time"}
+
+	for _, test := range exportTests {
+		buf := &bytes.Buffer{}
+		err := playlist.Export(buf, test.format, songs, columns)
+		if !test.success {
+			assert.NotNil(t, err, "format %s", test.format)
+			continue
+		}
+		assert.Nil(t, err, "format %s", test.format)
+		assert.Equal(t, test.output, buf.String(), "format %s", test.format)
+	}
+}
@@ -0,0 +1,272 @@
+package playlist
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Entry is a single entry of a playlist file. Apart from the location, all
+// fields are optional.
+type Entry struct {
+	Location string
+	Artist   string
+	Title    string
+	Album    string
+	Time     int
+}
+
+// importers maps each import format to the function that reads it.
+var importers = map[string]func(r io.Reader) ([]Entry, error){
+	"m3u":  importM3U,
+	"m3u8": importM3U,
+	"pls":  importPLS,
+	"xspf": importXSPF,
+}
+
+// ImportFormats returns the names of all formats supported by Import.
+func ImportFormats() []string {
+	formats := make([]string, 0, len(importers))
+	for format := range importers {
+		formats = append(formats, format)
+	}
+	sort.Strings(formats)
+	return formats
+}
+
+// CanImport returns true if Import supports the given format.
+func CanImport(format string) bool {
+	_, ok := importers[format]
+	return ok
+}
+
+// FormatFromPath returns the playlist format of a file, based on its extension.
+func FormatFromPath(filename string) string {
+	return strings.ToLower(strings.TrimPrefix(filepath.Ext(filename), "."))
+}
+
+// Import reads playlist entries from r using the given format.
+func Import(r io.Reader, format string) ([]Entry, error) {
+	importer, ok := importers[format]
+	if !ok {
+		return nil, fmt.Errorf("Unknown playlist format '%s'", format)
+	}
+	return importer(r)
+}
+
+// URI returns the location of the entry relative to the music directory,
+// which is how MPD identifies songs. Relative locations are relative to the
+// directory of the playlist file. If that does not lead to a file inside the
+// music directory, they are assumed to be relative to the music directory
+// already, as in playlists saved by MPD. If the entry is an absolute path
+// outside of the music directory, false is returned.
+func (e Entry) URI(musicDir, playlistDir string) (string, bool) {
+	location := e.Location
+
+	if strings.HasPrefix(location, "file://") {
+		u, err := url.Parse(location)
+		if err != nil {
+			return "", false
+		}
+		location = u.Path
+	} else if strings.Contains(location, "://") {
+		return location, true
+	}
+
+	if !path.IsAbs(location) {
+		if len(playlistDir) > 0 {
+			if uri, ok := relativeTo(musicDir, filepath.Join(playlistDir, location)); ok {
+				return uri, true
+			}
+		}
+		return path.Clean(location), true
+	}
+
+	return relativeTo(musicDir, location)
+}
+
+// relativeTo returns an absolute path relative to the music directory. If the
+// path is outside of the music directory, false is returned.
+func relativeTo(musicDir, location string) (string, bool) {
+	if len(musicDir) == 0 {
+		return "", false
+	}
+	rel, err := filepath.Rel(musicDir, location)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, "../") {
+		return "", false
+	}
+	return filepath.ToSlash(rel), true
+}
+
+// Tags returns the known tags of the entry. If the entry has neither artist
+// nor title, the file name without extension is used as the title.
+func (e Entry) Tags() map[string]string {
+	tags := make(map[string]string)
+	if len(e.Artist) > 0 {
+		tags["artist"] = e.Artist
+	}
+	if len(e.Title) > 0 {
+		tags["title"] = e.Title
+	}
+	if len(e.Album) > 0 {
+		tags["album"] = e.Album
+	}
+	if len(tags) == 0 {
+		base := path.Base(e.Location)
+		tags["title"] = strings.TrimSuffix(base, path.Ext(base))
+	}
+	return tags
+}
+
+// latin1 converts text in the ISO 8859-1 encoding to UTF-8.
+func latin1(s string) string {
+	runes := make([]rune, len(s))
+	for i := 0; i < len(s); i++ {
+		runes[i] = rune(s[i])
+	}
+	return string(runes)
+}
+
+// splitTitle splits the display title of M3U and PLS files into artist and
+// title, if the title has the form "artist - title".
+func splitTitle(s string) (string, string) {
+	parts := strings.SplitN(s, " - ", 2)
+	if len(parts) < 2 {
+		return "", s
+	}
+	return parts[0], parts[1]
+}
+
+// importM3U reads a simple or extended M3U playlist. M3U files that are not
+// valid UTF-8 are assumed to be encoded in ISO 8859-1.
+func importM3U(r io.Reader) ([]Entry, error) {
+	entries := make([]Entry, 0)
+	next := Entry{Time: -1}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimPrefix(scanner.Text(), "\ufeff")
+		line = strings.TrimSpace(line)
+		if !utf8.ValidString(line) {
+			line = latin1(line)
+		}
+
+		switch {
+		case len(line) == 0:
+			continue
+		case strings.HasPrefix(line, "#EXTINF:"):
+			info := strings.SplitN(line[len("#EXTINF:"):], ",", 2)
+			if secs, err := strconv.Atoi(strings.TrimSpace(info[0])); err == nil {
+				next.Time = secs
+			}
+			if len(info) > 1 {
+				next.Artist, next.Title = splitTitle(strings.TrimSpace(info[1]))
+			}
+		case strings.HasPrefix(line, "#"):
+			continue
+		default:
+			next.Location = line
+			entries = append(entries, next)
+			next = Entry{Time: -1}
+		}
+	}
+
+	return entries, scanner.Err()
+}
+
+// importPLS reads a PLS playlist, where each entry is described by numbered
+// FileN, TitleN and LengthN keys.
+func importPLS(r io.Reader) ([]Entry, error) {
+	indexed := make(map[int]*Entry)
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(strings.TrimPrefix(scanner.Text(), "\ufeff"))
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		key, value := strings.ToLower(strings.TrimSpace(parts[0])), strings.TrimSpace(parts[1])
+
+		field := strings.TrimRight(key, "0123456789")
+		n, err := strconv.Atoi(key[len(field):])
+		if err != nil {
+			continue
+		}
+		entry, ok := indexed[n]
+		if !ok {
+			entry = &Entry{Time: -1}
+			indexed[n] = entry
+		}
+
+		switch field {
+		case "file":
+			entry.Location = value
+		case "title":
+			entry.Artist, entry.Title = splitTitle(value)
+		case "length":
+			if secs, err := strconv.Atoi(value); err == nil {
+				entry.Time = secs
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	keys := make([]int, 0, len(indexed))
+	for n := range indexed {
+		keys = append(keys, n)
+	}
+	sort.Ints(keys)
+
+	entries := make([]Entry, 0, len(keys))
+	for _, n := range keys {
+		if len(indexed[n].Location) > 0 {
+			entries = append(entries, *indexed[n])
+		}
+	}
+
+	return entries, nil
+}
+
+// importXSPF reads an XSPF playlist. Relative locations are decoded from URIs
+// into plain paths.
+func importXSPF(r io.Reader) ([]Entry, error) {
+	playlist := xspfPlaylist{}
+	if err := xml.NewDecoder(r).Decode(&playlist); err != nil {
+		return nil, fmt.Errorf("Invalid XSPF playlist: %s", err)
+	}
+
+	entries := make([]Entry, 0, len(playlist.Tracks))
+	for _, track := range playlist.Tracks {
+		location := strings.TrimSpace(track.Location)
+		if u, err := url.Parse(location); err == nil && len(u.Scheme) == 0 {
+			location = u.Path
+		}
+		if len(location) == 0 {
+			continue
+		}
+		entry := Entry{
+			Location: location,
+			Artist:   track.Creator,
+			Title:    track.Title,
+			Album:    track.Album,
+			Time:     -1,
+		}
+		if track.Duration > 0 {
+			entry.Time = track.Duration / 1000
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,119 @@
+package playlist_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/ambientsound/pms/playlist"
+	"github.com/stretchr/testify/assert"
+)
+
+var importTests = []struct {
+	format  string
+	input   string
+	success bool
+	entries []playlist.Entry
+}{
+	{"m3u", "#EXTM3U\n#EXTINF:185,Foo - Bar - Baz\nfoo/bar.flac\r\n\n# comment\n/music/baz.mp3\n", true, []playlist.Entry{
+		{Location: "foo/bar.flac", Artist: "Foo", Title: "Bar - Baz", Time: 185},
+		{Location: "/music/baz.mp3", Time: -1},
+	}},
+	{"m3u", "\ufeff#EXTINF:-1,Qux\nqux.ogg\n", true, []playlist.Entry{
+		{Location: "qux.ogg", Title: "Qux", Time: -1},
+	}},
+	{"m3u", "caf\xe9.mp3\n", true, []playlist.Entry{
+		{Location: "café.mp3", Time: -1},
+	}},
+	{"pls", "[playlist]\nNumberOfEntries=2\nFile2=b.mp3\nFile1=a.mp3\nTitle1=Foo - Bar\nLength1=60\nTitle3=No file\nVersion=2\n", true, []playlist.Entry{
+		{Location: "a.mp3", Artist: "Foo", Title: "Bar", Time: 60},
+		{Location: "b.mp3", Time: -1},
+	}},
+	{"xspf", `<?xml version="1.0" encoding="UTF-8"?>
+<playlist version="1" xmlns="http://xspf.org/ns/0/">
+  <trackList>
+    <track>
+      <location>foo/01%20bar%20&amp;%20baz.flac</location>
+      <title>Bar &amp; Baz</title>
+      <creator>Foo</creator>
+      <album>Qux</album>
+      <duration>185000</duration>
+    </track>
+    <track>
+      <location>file:///music/baz.mp3</location>
+    </track>
+  </trackList>
+</playlist>
+`, true, []playlist.Entry{
+		{Location: "foo/01 bar & baz.flac", Artist: "Foo", Title: "Bar & Baz", Album: "Qux", Time: 185},
+		{Location: "file:///music/baz.mp3", Time: -1},
+	}},
+	{"xspf", "<playlist>", false, nil},
+	{"csv", "", false, nil},
+}
+
+func TestImport(t *testing.T) {
+	for i, test := range importTests {
+		entries, err := playlist.Import(strings.NewReader(test.input), test.format)
+		if !test.success {
+			assert.NotNil(t, err, "test %d", i)
+			continue
+		}
+		assert.Nil(t, err, "test %d", i)
+		assert.Equal(t, test.entries, entries, "test %d", i)
+	}
+}
+
+// Test that exported playlists can be imported again.
+func TestExportImport(t *testing.T) {
+	for _, format := range []string{"m3u", "xspf"} {
+		buf := &bytes.Buffer{}
+		err := playlist.Export(buf, format, exportSongs(), nil)
+		assert.Nil(t, err)
+		entries, err := playlist.Import(buf, format)
+		assert.Nil(t, err)
+		assert.Len(t, entries, 2, "format %s", format)
+		assert.Equal(t, "foo/01 bar & baz.flac", entries[0].Location, "format %s", format)
+		assert.Equal(t, "Foo", entries[0].Artist, "format %s", format)
+		assert.Equal(t, "Bar & Baz", entries[0].Title, "format %s", format)
+		assert.Equal(t, 185, entries[0].Time, "format %s", format)
+		assert.Equal(t, "untitled.mp3", entries[1].Location, "format %s", format)
+	}
+}
+
+var uriTests = []struct {
+	location    string
+	musicDir    string
+	playlistDir string
+	uri         string
+	ok          bool
+}{
+	{"foo/bar.flac", "", "", "foo/bar.flac", true},
+	{"./foo/../bar.flac", "/music", "", "bar.flac", true},
+	{"/music/foo/bar.flac", "/music", "", "foo/bar.flac", true},
+	{"/music/foo/bar.flac", "/music/", "", "foo/bar.flac", true},
+	{"file:///music/foo%20bar.flac", "/music", "", "foo bar.flac", true},
+	{"/music/foo/bar.flac", "", "", "", false},
+	{"/other/bar.flac", "/music", "", "", false},
+	{"/musicx/bar.flac", "/music", "", "", false},
+	{"http://example.com/stream", "/music", "", "http://example.com/stream", true},
+
+	// Relative to the playlist file
+	{"01 bar.flac", "/music", "/music/foo", "foo/01 bar.flac", true},
+	{"../Music/foo/bar.flac", "/home/user/Music", "/home/user/playlists", "foo/bar.flac", true},
+	{"foo/bar.flac", "/music", "/var/lib/mpd/playlists", "foo/bar.flac", true},
+	{"foo/bar.flac", "", "/music", "foo/bar.flac", true},
+}
+
+func TestEntryURI(t *testing.T) {
+	for _, test := range uriTests {
+		uri, ok := playlist.Entry{Location: test.location}.URI(test.musicDir, test.playlistDir)
+		assert.Equal(t, test.ok, ok, test.location)
+		assert.Equal(t, test.uri, uri, test.location)
+	}
+}
+
+func TestEntryTags(t *testing.T) {
+	assert.Equal(t, map[string]string{"artist": "Foo", "title": "Bar"}, playlist.Entry{Location: "x.mp3", Artist: "Foo", Title: "Bar"}.Tags())
+	assert.Equal(t, map[string]string{"title": "01 Bar"}, playlist.Entry{Location: "foo/01 Bar.mp3"}.Tags())
+}
@@ -0,0 +1,34 @@
+// Package playlist reads and writes playlist files, so that songlists can be
+// shared with other music players.
+package playlist
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/ambientsound/pms/song"
+)
+
+// title returns a human readable description of a song, as used in M3U and
+// XSPF files, where the artist is optional.
+func title(s *song.Song) string {
+	artist := s.StringTags["artist"]
+	title := s.StringTags["title"]
+	switch {
+	case len(title) == 0:
+		return path.Base(s.StringTags["file"])
+	case len(artist) == 0:
+		return title
+	default:
+		return fmt.Sprintf("%s - %s", artist, title)
+	}
+}
+
+// duration returns the length of a song in seconds, or -1 if the length is
+// not known.
+func duration(s *song.Song) int {
+	if len(s.StringTags["time"]) == 0 {
+		return -1
+	}
+	return s.Time
+}
@@ -0,0 +1,204 @@
+package pms
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/ambientsound/pms/console"
+	"github.com/ambientsound/pms/constants"
+	"github.com/ambientsound/pms/db"
+	"github.com/ambientsound/pms/message"
+)
+
+// batchIndexTimeout is how long batch mode waits for another instance of PMS
+// to release the search index.
+const batchIndexTimeout = 5 * time.Second
+
+// batchOutput is a single line of output in batch mode.
+type batchOutput struct {
+	Command  string `json:"command"`
+	Severity string `json:"severity"`
+	Text     string `json:"text"`
+}
+
+// batchUI implements api.UI when running without a user interface. Functions
+// are run immediately, and nothing is ever drawn.
+type batchUI struct{}
+
+func (ui *batchUI) PostFunc(f func()) {
+	f()
+}
+
+func (ui *batchUI) Refresh() {
+}
+
+// batchSonglistWidget implements api.SonglistWidget when running without a
+// user interface. The entire songlist is considered to be visible.
+type batchSonglistWidget struct {
+	database *db.Instance
+}
+
+func (w *batchSonglistWidget) GetVisibleBoundaries() (int, int) {
+	return 0, w.database.Panel().Current().Len() - 1
+}
+
+func (w *batchSonglistWidget) ScrollViewport(delta int, movecursor bool) {
+	if movecursor {
+		w.database.Panel().Current().MoveCursor(delta)
+	}
+}
+
+func (w *batchSonglistWidget) Size() (int, int) {
+	return 0, w.database.Panel().Current().Len()
+}
+
+// batchMultibar implements api.MultibarWidget when running without a user
+// interface. Only normal mode is available.
+type batchMultibar struct{}
+
+func (m *batchMultibar) Message() message.Message {
+	return message.Message{}
+}
+
+func (m *batchMultibar) Mode() int {
+	return constants.MultibarModeNormal
+}
+
+func (m *batchMultibar) SetMode(mode int) error {
+	if mode != constants.MultibarModeNormal {
+		return fmt.Errorf("Input modes are not available in batch mode")
+	}
+	return nil
+}
+
+// RunBatch connects to MPD, synchronizes the queue, library and
+// search index, and then runs each command line in order without drawing
+// anything. Messages produced by the commands are written to w as JSON
+// objects, one per line. Execution stops at the first error, which is
+// both written to w and returned.
+func (pms *PMS) RunBatch(lines []string, w io.Writer) error {
+	encoder := json.NewEncoder(w)
+
+	// Messages produced up until now, such as configuration file errors, are
+	// not the result of any command.
+	for _, msg := range pms.drainMessages() {
+		message.Log(msg)
+	}
+	pms.handleBatchOptions()
+
+	go pms.Connection.Run()
+
+	if err := pms.waitBatchConnected(); err != nil {
+		encoder.Encode(batchOutput{Severity: "error", Text: err.Error()})
+		return err
+	}
+
+	for _, line := range lines {
+		console.Log("Batch command: '%s'", line)
+		err := pms.CLI.Execute(line)
+		pms.handleBatchOptions()
+		pms.drainListEvents()
+
+		for _, msg := range pms.drainMessages() {
+			if msg.Type != message.Normal {
+				continue
+			}
+			encoder.Encode(batchOutput{line, msg.SeverityName(), msg.Text})
+		}
+
+		if err != nil {
+			encoder.Encode(batchOutput{line, "error", err.Error()})
+			return err
+		}
+	}
+
+	return nil
+}
+
+// waitBatchConnected blocks until the MPD connection is ready and all data
+// has been synchronized. Any connection error is returned immediately instead
+// of retrying.
+func (pms *PMS) waitBatchConnected() error {
+	for {
+		select {
+		case <-pms.Connection.Connected:
+			return pms.syncBatch()
+		case msg := <-pms.EventMessage:
+			message.Log(msg)
+			if msg.Severity == message.Error {
+				return fmt.Errorf("%s", msg.Text)
+			}
+		}
+	}
+}
+
+// syncBatch retrieves the current song, queue and library, and waits until
+// the search index is up to date.
+func (pms *PMS) syncBatch() error {
+	if err := pms.UpdateCurrentSong(); err != nil {
+		return err
+	}
+	if err := pms.SyncQueue(); err != nil {
+		return err
+	}
+	if err := pms.SyncLibrary(); err != nil {
+		return err
+	}
+
+	console.Log("Waiting for search index to be synchronized...")
+	pms.database.Library().WaitReIndex()
+
+	panel := pms.database.Panel()
+	panel.Replace(pms.database.Queue())
+	panel.Replace(pms.database.Library())
+
+	for _, msg := range pms.drainMessages() {
+		message.Log(msg)
+	}
+
+	return nil
+}
+
+// handleBatchOptions applies options that have been changed since the last
+// call. Options that only concern the user interface are ignored.
+func (pms *PMS) handleBatchOptions() {
+	for {
+		select {
+		case key := <-pms.EventOption:
+			console.Log("Option '%s' has been changed", key)
+			switch key {
+			case "tagseparator":
+				pms.setupTagSeparator()
+			}
+		default:
+			return
+		}
+	}
+}
+
+// drainListEvents discards list change notifications, as there are no columns
+// to recalculate without a user interface.
+func (pms *PMS) drainListEvents() {
+	for {
+		select {
+		case <-pms.EventList:
+		default:
+			return
+		}
+	}
+}
+
+// drainMessages returns all messages waiting in the message queue.
+func (pms *PMS) drainMessages() []message.Message {
+	messages := make([]message.Message, 0)
+	for {
+		select {
+		case msg := <-pms.EventMessage:
+			messages = append(messages, msg)
+		default:
+			return messages
+		}
+	}
+}
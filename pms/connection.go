@@ -7,6 +7,7 @@ import (
 	"github.com/ambientsound/gompd/mpd"
 	"github.com/ambientsound/pms/console"
 	"github.com/ambientsound/pms/message"
+	pms_mpd "github.com/ambientsound/pms/mpd"
 )
 
 // Connection maintains connections to an MPD server. Two separate connections
@@ -24,6 +25,7 @@ type Connection struct {
 	messages   chan message.Message
 	mpdClient  *mpd.Client
 	mpdIdle    *mpd.Watcher
+	rawClient  *pms_mpd.RawClient
 }
 
 // NewConnection returns Connection.
@@ -67,6 +69,40 @@ func (c *Connection) MpdClient() (*mpd.Client, error) {
 	return c.mpdClient, nil
 }
 
+// RawClient returns the connection used for commands that are not supported
+// by the regular MPD client. As with the control connection, the connection
+// is pinged first, and re-established if it has timed out.
+func (c *Connection) RawClient() (*pms_mpd.RawClient, error) {
+	var err error
+
+	if c.mpdIdle == nil {
+		return nil, fmt.Errorf("MPD connection is not ready.")
+	}
+
+	addr := makeAddress(c.Host, c.Port)
+
+	if c.rawClient != nil {
+		err = c.rawClient.Ping()
+		if err == nil {
+			return c.rawClient, nil
+		}
+		console.Log("MPD raw connection timeout.")
+		c.rawClient.Close()
+		c.rawClient = nil
+	}
+
+	console.Log("Establishing MPD raw connection to %+v...", addr)
+
+	c.rawClient, err = pms_mpd.DialRaw(addr.network, addr.addr, c.Password)
+	if err != nil {
+		return nil, fmt.Errorf("MPD connection error: %s", err)
+	}
+
+	console.Log("Established MPD raw connection.")
+
+	return c.rawClient, nil
+}
+
 // Open sets the host, port, and password parameters, closes any existing
 // connections, and asynchronously connects to MPD as long as Run() is called.
 func (c *Connection) Open(host, port, password string) {
@@ -84,8 +120,12 @@ func (c *Connection) Close() {
 	if c.mpdIdle != nil {
 		c.mpdIdle.Close()
 	}
+	if c.rawClient != nil {
+		c.rawClient.Close()
+	}
 	c.mpdClient = nil
 	c.mpdIdle = nil
+	c.rawClient = nil
 }
 
 // Run is the main goroutine of Connection. This thread will maintain an IDLE
@@ -111,6 +151,9 @@ func (c *Connection) Run() {
 			c.Error("Error in MPD IDLE connection: %s", err)
 			c.mpdClient.Close()
 			c.mpdIdle.Close()
+			if c.rawClient != nil {
+				c.rawClient.Close()
+			}
 		}
 	}
 }
@@ -131,6 +174,7 @@ func (c *Connection) connectIdle() error {
 
 	c.mpdClient = nil
 	c.mpdIdle = nil
+	c.rawClient = nil
 
 	addr := makeAddress(c.Host, c.Port)
 
@@ -0,0 +1,27 @@
+package pms
+
+import (
+	"time"
+
+	"github.com/ambientsound/pms/console"
+	pms_mpd "github.com/ambientsound/pms/mpd"
+)
+
+// trackHistory records the current song in the listening history once it has
+// been playing for longer than the 'historythreshold' option.
+func (pms *PMS) trackHistory(status pms_mpd.PlayerStatus) {
+	currentSong := pms.database.CurrentSong()
+	threshold := pms.Options.IntValue("historythreshold")
+	if !pms.historyTracker.Due(status, currentSong, threshold) {
+		return
+	}
+
+	now := time.Now()
+
+	pms.ui.PostFunc(func() {
+		console.Log("Recording '%s' in listening history.", currentSong.StringTags["file"])
+		if err := pms.database.History().Record(currentSong, now); err != nil {
+			pms.Error("Error while writing listening history: %s", err)
+		}
+	})
+}
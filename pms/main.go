@@ -22,6 +22,10 @@ func (pms *PMS) Main() {
 			pms.handleEventQueue()
 		case <-pms.EventPlayer:
 			pms.handleEventPlayer()
+		case <-pms.EventList:
+			// Sent by commands that change the current list. Must always be
+			// consumed, or commands will block once the channel is full.
+			pms.handleEventList()
 		case key := <-pms.EventOption:
 			pms.handleEventOption(key)
 		case msg := <-pms.EventMessage:
@@ -41,6 +45,9 @@ func (pms *PMS) Main() {
 
 func (pms *PMS) handleQuitSignal() {
 	console.Log("Received quit signal, exiting.")
+	if pms.remote != nil {
+		pms.remote.Close()
+	}
 	pms.ui.Quit()
 }
 
@@ -48,6 +55,7 @@ func (pms *PMS) handleEventLibrary() {
 	console.Log("Song library updated in MPD, assigning to UI")
 	pms.ui.App.PostFunc(func() {
 		pms.database.Panel().Replace(pms.database.Library())
+		pms.database.Panel().RefreshLists()
 	})
 }
 
@@ -55,24 +63,54 @@ func (pms *PMS) handleEventQueue() {
 	console.Log("Queue updated in MPD, assigning to UI")
 	pms.ui.App.PostFunc(func() {
 		pms.database.Panel().Replace(pms.database.Queue())
+		pms.database.Panel().RefreshLists()
 	})
 }
 
 func (pms *PMS) handleEventOption(key string) {
 	console.Log("Option '%s' has been changed", key)
 	switch key {
+	case "mouse":
+		pms.setupMouse()
+	case "remote":
+		pms.setupRemote()
+	case "statusbar":
+		pms.setupStatusbar()
+	case "statusbarmessages":
+		pms.setupStatusbarMessages()
 	case "topbar":
 		pms.setupTopbar()
-	case "columns":
-		// list changed, FIXME
+	case "columns", "historycolumns", "librarycolumns", "queuecolumns":
+		pms.handleEventList()
+	case "tagseparator":
+		pms.setupTagSeparator()
+		pms.handleEventList()
 	}
 }
 
+// handleEventList updates the lists views, and makes the UI recalculate the
+// tracklist columns.
+func (pms *PMS) handleEventList() {
+	pms.ui.App.PostFunc(func() {
+		pms.database.Panel().RefreshLists()
+		pms.ui.ListChanged()
+	})
+}
+
 func (pms *PMS) handleEventPlayer() {
 }
 
 func (pms *PMS) handleEventMessage(msg message.Message) {
 	message.Log(msg)
+
+	if msg.Type == message.Normal {
+		pms.database.Messages().Add(msg)
+		pms.handleEventList()
+		if !pms.statusbarSeverities[msg.Severity] {
+			return
+		}
+	}
+
 	pms.ui.App.PostFunc(func() {
 		pms.ui.Multibar.SetMessage(msg)
 	})
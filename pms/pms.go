@@ -16,6 +16,7 @@ import (
 	"github.com/ambientsound/pms/message"
 	pms_mpd "github.com/ambientsound/pms/mpd"
 	"github.com/ambientsound/pms/options"
+	"github.com/ambientsound/pms/remote"
 	"github.com/ambientsound/pms/song"
 	"github.com/ambientsound/pms/songlist"
 	"github.com/ambientsound/pms/style"
@@ -40,6 +41,18 @@ type PMS struct {
 	// MPD connection object
 	Connection *Connection
 
+	// Remote control socket, if enabled
+	remote *remote.Server
+
+	// True when running commands without a user interface.
+	batch bool
+
+	// Listening history state of the current song
+	historyTracker songlist.HistoryTracker
+
+	// Message severities that are shown in the statusbar.
+	statusbarSeverities map[int]bool
+
 	// Local versions of MPD's queue and song library, in addition to the song library version that was indexed.
 	queueVersion   int
 	libraryVersion int
@@ -89,6 +102,9 @@ func (pms *PMS) Error(format string, a ...interface{}) {
 }
 
 func (pms *PMS) Wait() {
+	if pms.ui == nil {
+		return
+	}
 	pms.ui.Wait()
 }
 
@@ -139,8 +155,17 @@ func (pms *PMS) CurrentMpdClient() *mpd.Client {
 	return client
 }
 
+// CurrentRawClient returns the MPD connection used for commands that are not
+// supported by the regular MPD client.
+func (pms *PMS) CurrentRawClient() (*pms_mpd.RawClient, error) {
+	return pms.Connection.RawClient()
+}
+
 // CurrentSonglistWidget returns the current songlist.
 func (pms *PMS) CurrentSonglistWidget() api.SonglistWidget {
+	if pms.ui == nil {
+		return &batchSonglistWidget{pms.database}
+	}
 	return pms.ui.Songlist
 }
 
@@ -151,11 +176,17 @@ func (pms *PMS) Stylesheet() style.Stylesheet {
 
 // Multibar returns the multibar widget.
 func (pms *PMS) Multibar() api.MultibarWidget {
+	if pms.ui == nil {
+		return &batchMultibar{}
+	}
 	return pms.ui.Multibar
 }
 
 // UI returns the tcell UI widget.
 func (pms *PMS) UI() api.UI {
+	if pms.ui == nil {
+		return &batchUI{}
+	}
 	return pms.ui
 }
 
@@ -164,7 +195,9 @@ func (pms *PMS) RunTicker() {
 	ticker := time.NewTicker(time.Millisecond * 1000)
 	defer ticker.Stop()
 	for range ticker.C {
-		pms.database.SetPlayerStatus(pms.database.PlayerStatus().Tick())
+		status := pms.database.PlayerStatus().Tick()
+		pms.database.SetPlayerStatus(status)
+		pms.trackHistory(status)
 		pms.EventPlayer <- 0
 	}
 }
@@ -206,8 +239,19 @@ func (pms *PMS) SyncLibrary() error {
 		}
 
 		library.SetVersion(version)
-		err = library.OpenIndex(index.Path(pms.Connection.Host, pms.Connection.Port))
+
+		// The search index is locked for as long as another instance of PMS
+		// has it open. Batch mode gives up after a while instead of waiting
+		// until that instance exits.
+		var timeout time.Duration
+		if pms.batch {
+			timeout = batchIndexTimeout
+		}
+		err = library.OpenIndex(index.Path(pms.Connection.Host, pms.Connection.Port), timeout)
 		if err != nil {
+			if pms.batch {
+				return err
+			}
 			console.Log("Error opening search index: %s", err)
 		}
 
@@ -262,6 +306,20 @@ func (pms *PMS) SyncQueue() error {
 		newQueue.SetCursor(queue.Cursor())
 	}
 
+	// Preserve the selection by song ID, so that selected songs stay selected
+	// when they are moved around in the queue.
+	selected := make(map[int]bool)
+	for i := 0; i < queue.Len(); i++ {
+		if queue.Selected(i) {
+			selected[queue.Song(i).ID] = true
+		}
+	}
+	for i, s := range newQueue.Songs() {
+		if selected[s.ID] {
+			newQueue.SetSelected(i, true)
+		}
+	}
+
 	pms.database.SetQueue(newQueue)
 	pms.queueVersion = status.Playlist
 	console.Log("Queue at version %d.", pms.queueVersion)
@@ -269,8 +327,11 @@ func (pms *PMS) SyncQueue() error {
 	return nil
 }
 
+// retrieveLibrary retrieves the song library from MPD. The raw connection is
+// used, so that songs with several values for the same tag, such as multiple
+// artists, keep all of them.
 func (pms *PMS) retrieveLibrary() (*songlist.Library, error) {
-	client, err := pms.Connection.MpdClient()
+	client, err := pms.Connection.RawClient()
 	if err != nil {
 		return nil, err
 	}
@@ -286,33 +347,35 @@ func (pms *PMS) retrieveLibrary() (*songlist.Library, error) {
 
 	timer = time.Now()
 	s := songlist.NewLibrary()
-	s.AddFromAttrlist(list)
+	s.AddFromMultiAttrlist(list, pms.database.Tagging())
 	console.Log("Built library in %s", time.Since(timer).String())
 
 	return s, nil
 }
 
+// retrieveQueue retrieves the songs in the queue that have changed since the
+// last known queue version.
 func (pms *PMS) retrieveQueue() (*songlist.Queue, error) {
-	client, err := pms.Connection.MpdClient()
+	client, err := pms.Connection.RawClient()
 	if err != nil {
 		return nil, err
 	}
 
 	timer := time.Now()
-	list, err := client.PlChanges(pms.queueVersion, -1, -1)
+	list, err := client.PlChanges(pms.queueVersion)
 	if err != nil {
 		return nil, err
 	}
 	console.Log("PlChanges in %s", time.Since(timer).String())
 
-	s := songlist.NewQueue(pms.CurrentMpdClient)
-	s.AddFromAttrlist(list)
+	s := songlist.NewQueue(pms.CurrentMpdClient, pms.CurrentRawClient)
+	s.AddFromMultiAttrlist(list, pms.database.Tagging())
 	return s, nil
 }
 
 // UpdateCurrentSong stores a local copy of the currently playing song.
 func (pms *PMS) UpdateCurrentSong() error {
-	client, err := pms.Connection.MpdClient()
+	client, err := pms.Connection.RawClient()
 	if err != nil {
 		return err
 	}
@@ -322,10 +385,10 @@ func (pms *PMS) UpdateCurrentSong() error {
 		return err
 	}
 
-	console.Log("MPD current song: %s", attrs["file"])
-
 	s := song.New()
-	s.SetTags(attrs)
+	s.SetMultiTags(song.MultiTaglist(attrs), pms.database.Tagging())
+
+	console.Log("MPD current song: %s", s.StringTags["file"])
 	pms.database.SetCurrentSong(s)
 
 	pms.EventPlayer <- 0
@@ -367,10 +430,26 @@ func (pms *PMS) UpdatePlayerStatus() error {
 	status.Bitrate, _ = strconv.Atoi(attrs["bitrate"])
 	status.Playlist, _ = strconv.Atoi(attrs["playlist"])
 	status.PlaylistLength, _ = strconv.Atoi(attrs["playlistlength"])
-	status.Song, _ = strconv.Atoi(attrs["song"])
-	status.SongID, _ = strconv.Atoi(attrs["songid"])
 	status.Volume, _ = strconv.Atoi(attrs["volume"])
 
+	// The current and next songs are not present in the status when there is none.
+	status.Song = song.NullPosition
+	status.SongID = song.NullID
+	status.NextSong = song.NullPosition
+	status.NextSongID = song.NullID
+	if current, err := strconv.Atoi(attrs["song"]); err == nil {
+		status.Song = current
+	}
+	if current, err := strconv.Atoi(attrs["songid"]); err == nil {
+		status.SongID = current
+	}
+	if next, err := strconv.Atoi(attrs["nextsong"]); err == nil {
+		status.NextSong = next
+	}
+	if next, err := strconv.Atoi(attrs["nextsongid"]); err == nil {
+		status.NextSongID = next
+	}
+
 	status.Elapsed, _ = strconv.ParseFloat(attrs["elapsed"], 64)
 	status.ElapsedPercentage, _ = strconv.ParseFloat(attrs["elapsedpercentage"], 64)
 	status.MixRampDB, _ = strconv.ParseFloat(attrs["mixrampdb"], 64)
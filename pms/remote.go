@@ -0,0 +1,81 @@
+package pms
+
+import (
+	"os"
+
+	"github.com/ambientsound/pms/console"
+	"github.com/ambientsound/pms/input"
+	"github.com/ambientsound/pms/message"
+	"github.com/ambientsound/pms/remote"
+)
+
+// setupRemote opens or closes the remote control socket, depending on the
+// value of the 'remote' option.
+func (pms *PMS) setupRemote() {
+	enabled := pms.Options.BoolValue("remote")
+
+	switch {
+	case enabled && pms.remote == nil:
+		path := remote.Path(os.Getpid())
+		server, err := remote.Listen(path, pms.remoteExec)
+		if err != nil {
+			pms.Error("Error while opening remote control socket: %s", err)
+			return
+		}
+		pms.remote = server
+		go pms.remote.Serve()
+		pms.Message("Listening for remote commands on %s", path)
+
+	case !enabled && pms.remote != nil:
+		console.Log("Closing remote control socket.")
+		pms.remote.Close()
+		pms.remote = nil
+	}
+}
+
+// remoteExec runs a command line received on the remote control socket. The
+// command is executed on the UI goroutine, and any messages produced by the
+// command are returned as output, in addition to being shown in the statusbar.
+//
+// Messages are collected while the command runs, so that commands producing
+// many messages never block the UI goroutine.
+func (pms *PMS) remoteExec(line string) ([]string, error) {
+	messages := make(chan message.Message, 1024)
+	cli := input.NewCLI(pms.api(messages))
+	done := make(chan error, 1)
+
+	pms.ui.PostFunc(func() {
+		done <- cli.Execute(line)
+		pms.ui.App.Update()
+	})
+
+	output := make([]string, 0)
+	collect := func(msg message.Message) {
+		// Never wait for the main loop; the message is still returned to
+		// the remote client.
+		select {
+		case pms.EventMessage <- msg:
+		default:
+			console.Log("Message queue is full, not showing remote message: %s", msg.Text)
+		}
+		if msg.Type == message.Normal {
+			output = append(output, msg.Text)
+		}
+	}
+
+	for {
+		select {
+		case msg := <-messages:
+			collect(msg)
+		case err := <-done:
+			for {
+				select {
+				case msg := <-messages:
+					collect(msg)
+				default:
+					return output, err
+				}
+			}
+		}
+	}
+}
@@ -1,6 +1,8 @@
 package pms
 
 import (
+	"path"
+	"strings"
 	"time"
 
 	"github.com/ambientsound/pms/api"
@@ -10,13 +12,46 @@ import (
 	"github.com/ambientsound/pms/input/keys"
 	"github.com/ambientsound/pms/message"
 	"github.com/ambientsound/pms/options"
+	"github.com/ambientsound/pms/song"
 	"github.com/ambientsound/pms/songlist"
 	"github.com/ambientsound/pms/style"
 	"github.com/ambientsound/pms/topbar"
 	"github.com/ambientsound/pms/widgets"
+	"github.com/ambientsound/pms/xdg"
 )
 
+// New returns PMS, with the user interface started.
 func New() (*PMS, error) {
+	pms := newPMS()
+
+	err := pms.setupUI()
+	if err != nil {
+		return nil, err
+	}
+
+	pms.CLI = input.NewCLI(pms.API())
+
+	return pms, nil
+}
+
+// NewBatch returns PMS without a user interface. Commands can be run
+// using RunBatch.
+func NewBatch() (*PMS, error) {
+	pms := newPMS()
+	pms.batch = true
+
+	pms.database.Panel().Add(pms.database.Queue())
+	pms.database.Panel().Add(pms.database.Library())
+	pms.database.Panel().Add(pms.database.History())
+	pms.database.Panel().Activate(pms.database.Queue())
+
+	pms.CLI = input.NewCLI(pms.API())
+
+	return pms, nil
+}
+
+// newPMS initializes data structures common to both interactive and batch mode.
+func newPMS() *PMS {
 	pms := &PMS{}
 
 	pms.database = db.New()
@@ -29,31 +64,38 @@ func New() (*PMS, error) {
 	pms.EventQueue = make(chan int, 1024)
 	pms.QuitSignal = make(chan int, 1)
 	pms.stylesheet = make(style.Stylesheet)
+	pms.statusbarSeverities = map[int]bool{
+		message.Info:  true,
+		message.Error: true,
+	}
 
-	pms.database.SetQueue(songlist.NewQueue(pms.CurrentMpdClient))
+	pms.database.SetQueue(songlist.NewQueue(pms.CurrentMpdClient, pms.CurrentRawClient))
 	pms.database.SetLibrary(songlist.NewLibrary())
+	pms.database.SetHistory(songlist.NewHistory(path.Join(xdg.DataDirectory(), "history"), pms.database.Tagging()))
 
 	pms.Options = options.New()
 	pms.Options.AddDefaultOptions()
 
 	pms.Sequencer = keys.NewSequencer()
 
-	err := pms.setupUI()
-	if err != nil {
-		return nil, err
+	if err := pms.database.History().Load(); err != nil {
+		pms.Error("Error while reading listening history: %s", err)
 	}
 
-	pms.CLI = input.NewCLI(pms.API())
-
-	return pms, nil
+	return pms
 }
 
-// setupAPI creates an API object
+// API creates an API object
 func (pms *PMS) API() api.API {
+	return pms.api(pms.EventMessage)
+}
+
+// api creates an API object that sends messages to the given channel.
+func (pms *PMS) api(eventMessage chan message.Message) api.API {
 	return api.BaseAPI(
 		pms.Database,
 		pms.EventList,
-		pms.EventMessage,
+		eventMessage,
 		pms.EventOption,
 		pms.database.Library,
 		pms.CurrentMpdClient,
@@ -82,6 +124,7 @@ func (pms *PMS) setupUI() error {
 	pms.ui.Start()
 	pms.database.Panel().Add(queue)
 	pms.database.Panel().Add(pms.database.Library())
+	pms.database.Panel().Add(pms.database.History())
 	pms.database.Panel().Activate(queue)
 
 	console.Log("UI initialized in %s", time.Since(timer).String())
@@ -89,6 +132,47 @@ func (pms *PMS) setupUI() error {
 	return nil
 }
 
+func (pms *PMS) setupMouse() {
+	pms.ui.SetMouse(pms.Options.BoolValue("mouse"))
+}
+
+func (pms *PMS) setupStatusbar() {
+	config := pms.Options.StringValue("statusbar")
+	matrix, err := topbar.ParseStatusbar(pms.API(), config)
+	if err == nil {
+		pms.ui.Multibar.SetStatusbar(matrix)
+	} else {
+		pms.Error("Error in statusbar configuration: %s", err)
+	}
+}
+
+// setupTagSeparator joins the values of tags that have several values, such
+// as songs with multiple artists, using the tag separator.
+func (pms *PMS) setupTagSeparator() {
+	tagging := pms.database.Tagging()
+	tagging.SetSeparator(pms.Options.StringValue("tagseparator"))
+	pms.database.UpdateSongs(func(s *song.Song) {
+		s.Update(tagging)
+	})
+}
+
+func (pms *PMS) setupStatusbarMessages() {
+	severities := make(map[int]bool)
+	for _, name := range strings.Split(pms.Options.StringValue("statusbarmessages"), ",") {
+		name = strings.TrimSpace(name)
+		if len(name) == 0 {
+			continue
+		}
+		severity, err := message.ParseSeverity(name)
+		if err != nil {
+			pms.Error("Error in statusbarmessages option: %s", err)
+			continue
+		}
+		severities[severity] = true
+	}
+	pms.statusbarSeverities = severities
+}
+
 func (pms *PMS) setupTopbar() {
 	config := pms.Options.StringValue("topbar")
 	matrix, err := topbar.Parse(pms.API(), config)
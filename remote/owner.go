@@ -0,0 +1,23 @@
+//go:build !windows
+// +build !windows
+
+package remote
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// checkPrivate returns an error if the directory is not owned by the current
+// user, or if it is accessible to other users.
+func checkPrivate(dir string, info os.FileInfo) error {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok || int(stat.Uid) != os.Getuid() {
+		return fmt.Errorf("%s is not owned by the current user", dir)
+	}
+	if perm := info.Mode().Perm(); perm != 0700 {
+		return fmt.Errorf("%s has mode %04o, but it must be 0700", dir, perm)
+	}
+	return nil
+}
@@ -0,0 +1,206 @@
+// Package remote provides a control socket, through which other programs can
+// run commands in a running PMS instance.
+//
+// The protocol is line based. Clients write one command line at a time, and
+// the server answers each line with a single JSON object terminated by a
+// newline.
+package remote
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+
+	"github.com/ambientsound/pms/console"
+	"github.com/ambientsound/pms/xdg"
+)
+
+// Response is the reply to a single command line.
+type Response struct {
+	Success bool     `json:"success"`
+	Error   string   `json:"error,omitempty"`
+	Output  []string `json:"output"`
+}
+
+// Executor runs a command line, and returns any text output produced by the
+// command, along with the command error, if any.
+type Executor func(line string) ([]string, error)
+
+// Server listens for commands on a UNIX socket.
+type Server struct {
+	path     string
+	listener net.Listener
+	exec     Executor
+}
+
+// Directory returns the directory where control sockets are placed.
+func Directory() string {
+	return xdg.RuntimeDirectory()
+}
+
+// Path returns the control socket path of the PMS instance with the given process ID.
+func Path(pid int) string {
+	return path.Join(Directory(), fmt.Sprintf("%d.sock", pid))
+}
+
+// Listen creates a control socket at the specified path, and returns Server.
+// Any existing file at the same path is removed. The socket directory must be
+// private to the current user.
+func Listen(socketPath string, exec Executor) (*Server, error) {
+	dir := path.Dir(socketPath)
+	err := os.MkdirAll(dir, os.ModeDir|0700)
+	if err != nil {
+		return nil, fmt.Errorf("while creating %s: %s", dir, err)
+	}
+
+	if err = checkDirectory(dir); err != nil {
+		return nil, err
+	}
+
+	// The socket path is unique to this process, so any file found here is
+	// left over from a previous process with the same PID.
+	if err = os.Remove(socketPath); err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("while removing stale socket %s: %s", socketPath, err)
+	}
+
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Server{
+		path:     socketPath,
+		listener: listener,
+		exec:     exec,
+	}, nil
+}
+
+// checkDirectory makes sure that the socket directory is a directory, and not
+// a symlink, which is owned by the current user and inaccessible to others.
+// The fallback directory in /tmp might otherwise have been created by another
+// user in order to take over the control socket.
+func checkDirectory(dir string) error {
+	info, err := os.Lstat(dir)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", dir)
+	}
+	return checkPrivate(dir, info)
+}
+
+// Path returns the path to the control socket.
+func (s *Server) Path() string {
+	return s.path
+}
+
+// Serve accepts connections until the server is closed. Each connection is
+// handled in a separate goroutine.
+func (s *Server) Serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			console.Log("Remote control socket closed: %s", err)
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+// Close stops listening for connections, and removes the socket file.
+func (s *Server) Close() error {
+	err := s.listener.Close()
+	os.Remove(s.path)
+	return err
+}
+
+// handle reads command lines from a connection, executes them, and writes the
+// response back to the client.
+func (s *Server) handle(conn net.Conn) {
+	defer conn.Close()
+
+	scanner := bufio.NewScanner(conn)
+	encoder := json.NewEncoder(conn)
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		console.Log("Remote command: '%s'", line)
+
+		output, err := s.exec(line)
+		response := Response{
+			Success: err == nil,
+			Output:  output,
+		}
+		if response.Output == nil {
+			response.Output = make([]string, 0)
+		}
+		if err != nil {
+			response.Error = err.Error()
+		}
+
+		if err = encoder.Encode(response); err != nil {
+			console.Log("Error while writing to remote control client: %s", err)
+			return
+		}
+	}
+}
+
+// Send connects to the control socket at the specified path, runs a single
+// command line, and returns the response.
+func Send(socketPath, line string) (*Response, error) {
+	conn, err := net.Dial("unix", socketPath)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if _, err = fmt.Fprintln(conn, line); err != nil {
+		return nil, err
+	}
+
+	response := &Response{}
+	err = json.NewDecoder(conn).Decode(response)
+	if err != nil {
+		return nil, fmt.Errorf("while reading response: %s", err)
+	}
+
+	return response, nil
+}
+
+// Find returns the path to the control socket of the most recently started
+// PMS instance.
+func Find() (string, error) {
+	paths, err := filepath.Glob(path.Join(Directory(), "*.sock"))
+	if err != nil {
+		return "", err
+	}
+
+	// Order sockets by modification time, newest first, and return the
+	// first one that is accepting connections.
+	mtimes := make(map[string]int64, len(paths))
+	for _, p := range paths {
+		if stat, err := os.Stat(p); err == nil {
+			mtimes[p] = stat.ModTime().UnixNano()
+		}
+	}
+	sort.SliceStable(paths, func(a, b int) bool {
+		return mtimes[paths[a]] > mtimes[paths[b]]
+	})
+
+	for _, p := range paths {
+		conn, err := net.Dial("unix", p)
+		if err != nil {
+			continue
+		}
+		conn.Close()
+		return p, nil
+	}
+
+	return "", fmt.Errorf("No running PMS instance found in %s", Directory())
+}
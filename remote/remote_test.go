@@ -0,0 +1,77 @@
+package remote_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/ambientsound/pms/remote"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var remoteTests = []struct {
+	input    string
+	response remote.Response
+}{
+	{`play`, remote.Response{true, ``, []string{`play`}}},
+	{`volume +2`, remote.Response{true, ``, []string{`volume +2`}}},
+	{`fail`, remote.Response{false, `fail`, []string{}}},
+}
+
+func executor(line string) ([]string, error) {
+	if line == "fail" {
+		return nil, fmt.Errorf("fail")
+	}
+	return []string{line}, nil
+}
+
+func TestRemote(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pms-remote")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	socketPath := path.Join(dir, "test.sock")
+	server, err := remote.Listen(socketPath, executor)
+	require.Nil(t, err)
+	go server.Serve()
+
+	for n, test := range remoteTests {
+		t.Logf("### Test %d: '%s'", n+1, test.input)
+		response, err := remote.Send(socketPath, test.input)
+		assert.Nil(t, err)
+		assert.Equal(t, test.response, *response)
+	}
+
+	assert.Nil(t, server.Close())
+	_, err = os.Stat(socketPath)
+	assert.True(t, os.IsNotExist(err))
+}
+
+// Test that the control socket is only placed in private directories.
+func TestRemoteDirectory(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pms-remote")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	// Missing directories are created.
+	socketPath := path.Join(dir, "private", "test.sock")
+	server, err := remote.Listen(socketPath, executor)
+	require.Nil(t, err)
+	server.Close()
+
+	// Directories that other users can access are refused.
+	shared := path.Join(dir, "shared")
+	require.Nil(t, os.Mkdir(shared, 0700))
+	require.Nil(t, os.Chmod(shared, 0777))
+	_, err = remote.Listen(path.Join(shared, "test.sock"), executor)
+	assert.NotNil(t, err)
+
+	// Symlinks are refused, even when pointing to a private directory.
+	link := path.Join(dir, "link")
+	require.Nil(t, os.Symlink(path.Join(dir, "private"), link))
+	_, err = remote.Listen(path.Join(link, "test.sock"), executor)
+	assert.NotNil(t, err)
+}
@@ -15,10 +15,12 @@ import (
 type Song struct {
 	ID         int
 	Position   int
+	Prio       int
 	Time       int
 	Tags       Taglist
 	StringTags StringTaglist
 	SortTags   StringTaglist
+	MultiTags  MultiTaglist
 }
 
 type Tag []rune
@@ -27,6 +29,10 @@ type Taglist map[string]Tag
 
 type StringTaglist map[string]string
 
+// MultiTaglist holds every value of each tag, as tags such as artist and
+// genre may appear several times in a single song.
+type MultiTaglist map[string][]string
+
 const NullID int = -1
 const NullPosition int = -1
 
@@ -35,20 +41,88 @@ func New() (s *Song) {
 	s.Tags = make(Taglist)
 	s.StringTags = make(StringTaglist)
 	s.SortTags = make(StringTaglist)
+	s.MultiTags = make(MultiTaglist)
 	return
 }
 
+// SetTags sets the song tags, where each tag has a single value. Virtual tags
+// are not filled in.
 func (s *Song) SetTags(tags mpd.Attrs) {
-	s.Tags = make(Taglist)
+	multi := make(MultiTaglist, len(tags))
+	for key := range tags {
+		multi[key] = []string{tags[key]}
+	}
+	s.SetMultiTags(multi, nil)
+}
+
+// SetMultiTags sets the song tags, where each tag may have several values,
+// and calculates the remaining tags using tagging.
+func (s *Song) SetMultiTags(tags MultiTaglist, tagging *Tagging) {
+	s.MultiTags = make(MultiTaglist, len(tags))
 	for key := range tags {
 		lowKey := strings.ToLower(key)
-		s.Tags[lowKey] = []rune(tags[key])
-		s.StringTags[lowKey] = tags[key]
+		s.MultiTags[lowKey] = append(s.MultiTags[lowKey], tags[key]...)
+	}
+	s.Update(tagging)
+}
+
+// Update recalculates all tags from the song's tag values: multiple values
+// are joined using the tag separator, and derived, virtual, and sort tags are
+// filled in.
+func (s *Song) Update(tagging *Tagging) {
+	// Copies of a song share the same tag maps, so the maps are changed in place.
+	if s.Tags == nil {
+		s.Tags = make(Taglist)
+	}
+	for key := range s.Tags {
+		delete(s.Tags, key)
+	}
+	separator := tagging.Separator()
+	for key, values := range s.MultiTags {
+		value := strings.Join(values, separator)
+		s.Tags[key] = []rune(value)
+		s.StringTags[key] = value
 	}
 	s.AutoFill()
+	s.FillVirtualTags(tagging)
 	s.FillSortTags()
 }
 
+// Values returns all values of a tag. Tags that do not have multiple values,
+// such as virtual tags, are returned as a single value.
+func (s *Song) Values(tag string) []string {
+	if values, ok := s.MultiTags[tag]; ok {
+		return values
+	}
+	if value := s.StringTags[tag]; len(value) > 0 {
+		return []string{value}
+	}
+	return nil
+}
+
+// HasOneOfValues returns true if the song has at least one of the given
+// values for a tag.
+func (s *Song) HasOneOfValues(tag string, values []string) bool {
+	for _, x := range s.Values(tag) {
+		for _, y := range values {
+			if x == y {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// SharesValue returns true if both songs have at least one value of a tag in
+// common, or if neither of the songs have the tag.
+func (s *Song) SharesValue(other *Song, tag string) bool {
+	a, b := s.Values(tag), other.Values(tag)
+	if len(a) == 0 || len(b) == 0 {
+		return len(a) == len(b)
+	}
+	return s.HasOneOfValues(tag, b)
+}
+
 // NullID returns true if the song's ID is not present.
 func (s *Song) NullID() bool {
 	return s.ID == NullID
@@ -72,6 +146,11 @@ func (s *Song) AutoFill() {
 		s.Position = NullPosition
 	}
 
+	s.Prio, err = strconv.Atoi(s.StringTags["prio"])
+	if err != nil {
+		s.Prio = 0
+	}
+
 	s.Time, err = strconv.Atoi(s.StringTags["time"])
 	if err == nil {
 		s.Tags["time"] = utils.TimeRunes(s.Time)
@@ -16,6 +16,7 @@ var autofillTests = []struct {
 			Tags: song.Taglist{
 				"id":           []rune("1337"),
 				"pos":          []rune("33"),
+				"prio":         []rune("12"),
 				"time":         []rune("203"),
 				"date":         []rune("1986-04-22"),
 				"originaldate": []rune("1986-04-22"),
@@ -23,6 +24,7 @@ var autofillTests = []struct {
 			StringTags: song.StringTaglist{
 				"id":           "1337",
 				"pos":          "33",
+				"prio":         "12",
 				"time":         "203",
 				"date":         "1986-04-22",
 				"originaldate": "1986-04-22",
@@ -31,10 +33,12 @@ var autofillTests = []struct {
 		song.Song{
 			ID:       1337,
 			Position: 33,
+			Prio:     12,
 			Time:     203,
 			Tags: song.Taglist{
 				"id":           []rune("1337"),
 				"pos":          []rune("33"),
+				"prio":         []rune("12"),
 				"time":         []rune("03:23"),
 				"date":         []rune("1986-04-22"),
 				"year":         []rune("1986"),
@@ -44,6 +48,7 @@ var autofillTests = []struct {
 			StringTags: song.StringTaglist{
 				"id":           "1337",
 				"pos":          "33",
+				"prio":         "12",
 				"time":         "203",
 				"date":         "1986-04-22",
 				"year":         "1986",
@@ -62,3 +67,51 @@ func TestAutofill(t *testing.T) {
 	}
 
 }
+
+func TestMultiTags(t *testing.T) {
+	assert := assert.New(t)
+
+	s := song.New()
+	s.SetMultiTags(song.MultiTaglist{
+		"Artist": {"Foo", "Bar"},
+		"title":  {"Baz"},
+	}, nil)
+
+	assert.Equal([]string{"Foo", "Bar"}, s.Values("artist"))
+	assert.Equal("Foo; Bar", s.StringTags["artist"])
+	assert.Equal(song.Tag("Foo; Bar"), s.Tags["artist"])
+	assert.Equal([]string{"Baz"}, s.Values("title"))
+	assert.Nil(s.Values("album"))
+
+	tagging := song.NewTagging()
+	tagging.SetSeparator(" / ")
+	s.Update(tagging)
+	assert.Equal("Foo / Bar", s.StringTags["artist"])
+	assert.Equal([]string{"Foo", "Bar"}, s.Values("artist"))
+}
+
+var sharesValueTests = []struct {
+	a, b  []string
+	match bool
+}{
+	{[]string{"Foo"}, []string{"Foo"}, true},
+	{[]string{"Foo", "Bar"}, []string{"Bar"}, true},
+	{[]string{"Foo", "Bar"}, []string{"Baz", "Foo"}, true},
+	{[]string{"Foo"}, []string{"Bar"}, false},
+	{[]string{"Foo"}, nil, false},
+	{nil, nil, true},
+}
+
+func TestSharesValue(t *testing.T) {
+	for i, test := range sharesValueTests {
+		a, b := song.New(), song.New()
+		if test.a != nil {
+			a.SetMultiTags(song.MultiTaglist{"artist": test.a}, nil)
+		}
+		if test.b != nil {
+			b.SetMultiTags(song.MultiTaglist{"artist": test.b}, nil)
+		}
+		assert.Equal(t, test.match, a.SharesValue(b, "artist"), "test %d", i)
+		assert.Equal(t, test.match, b.SharesValue(a, "artist"), "test %d", i)
+	}
+}
@@ -0,0 +1,85 @@
+package song
+
+import (
+	"sync"
+)
+
+// DefaultTagSeparator is used to join multiple values of the same tag.
+const DefaultTagSeparator = "; "
+
+// Tagging holds the settings used to calculate song tags from their values:
+// the separator used to join multiple values of the same tag, and the
+// virtual tag definitions. A nil Tagging uses the default tag separator, and
+// has no virtual tags.
+type Tagging struct {
+	mutex       sync.RWMutex
+	separator   string
+	virtualTags []*VirtualTag
+}
+
+// NewTagging returns Tagging, using the default tag separator.
+func NewTagging() *Tagging {
+	return &Tagging{
+		separator:   DefaultTagSeparator,
+		virtualTags: make([]*VirtualTag, 0),
+	}
+}
+
+// SetSeparator sets the string used to join multiple values of the same tag.
+// Songs must be updated using Update() for the change to take effect.
+func (t *Tagging) SetSeparator(separator string) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.separator = separator
+}
+
+// Separator returns the string used to join multiple values of the same tag.
+func (t *Tagging) Separator() string {
+	if t == nil {
+		return DefaultTagSeparator
+	}
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+	return t.separator
+}
+
+// Define adds a virtual tag definition, or replaces the existing definition
+// with the same name. Virtual tags are evaluated in the order they are first
+// defined, and may refer to virtual tags defined before them.
+func (t *Tagging) Define(v *VirtualTag) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	for i := range t.virtualTags {
+		if t.virtualTags[i].Name == v.Name {
+			t.virtualTags[i] = v
+			return
+		}
+	}
+	t.virtualTags = append(t.virtualTags, v)
+}
+
+// Undefine removes a virtual tag definition. It returns false if no such
+// virtual tag exists.
+func (t *Tagging) Undefine(name string) bool {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	for i := range t.virtualTags {
+		if t.virtualTags[i].Name == name {
+			t.virtualTags = append(t.virtualTags[:i], t.virtualTags[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// VirtualTags returns all virtual tag definitions.
+func (t *Tagging) VirtualTags() []*VirtualTag {
+	if t == nil {
+		return nil
+	}
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+	defs := make([]*VirtualTag, len(t.virtualTags))
+	copy(defs, t.virtualTags)
+	return defs
+}
@@ -0,0 +1,174 @@
+package song
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// VirtualTag is a tag whose value is derived from other tags of the same
+// song. It is defined either by a template, such as
+// "${albumartist|artist} - ${album}", or by a regular expression applied to
+// another tag, such as "file:regex(\.(\w+)$)".
+type VirtualTag struct {
+	Name string
+	Expr string
+
+	template []templatePart
+	source   string
+	regex    *regexp.Regexp
+}
+
+// templatePart is either literal text, or a list of tags where the first
+// non-empty tag is used.
+type templatePart struct {
+	literal string
+	tags    []string
+}
+
+// reservedTags are tags which are used internally, and cannot be redefined.
+var reservedTags = map[string]bool{
+	"file":         true,
+	"id":           true,
+	"originalyear": true,
+	"pos":          true,
+	"prio":         true,
+	"time":         true,
+	"year":         true,
+}
+
+var regexTagPattern = regexp.MustCompile(`^(\w+):regex\((.*)\)$`)
+
+// ParseVirtualTag parses a virtual tag definition.
+func ParseVirtualTag(name, expr string) (*VirtualTag, error) {
+	name = strings.ToLower(name)
+	if len(name) == 0 {
+		return nil, fmt.Errorf("Virtual tag name cannot be empty")
+	}
+	if reservedTags[name] {
+		return nil, fmt.Errorf("The '%s' tag cannot be redefined", name)
+	}
+
+	v := &VirtualTag{
+		Name: name,
+		Expr: expr,
+	}
+
+	if match := regexTagPattern.FindStringSubmatch(expr); match != nil {
+		var err error
+		v.source = strings.ToLower(match[1])
+		v.regex, err = regexp.Compile(match[2])
+		if err != nil {
+			return nil, fmt.Errorf("Invalid regular expression: %s", err)
+		}
+		if v.source == name {
+			return nil, fmt.Errorf("Virtual tag '%s' cannot refer to itself", name)
+		}
+		return v, nil
+	}
+
+	template, err := parseTemplate(expr)
+	if err != nil {
+		return nil, err
+	}
+	for _, part := range template {
+		for _, tag := range part.tags {
+			if tag == name {
+				return nil, fmt.Errorf("Virtual tag '%s' cannot refer to itself", name)
+			}
+		}
+	}
+	v.template = template
+
+	return v, nil
+}
+
+// parseTemplate splits a template into literal text and tag references.
+func parseTemplate(expr string) ([]templatePart, error) {
+	parts := make([]templatePart, 0)
+	for len(expr) > 0 {
+		start := strings.Index(expr, "${")
+		if start < 0 {
+			parts = append(parts, templatePart{literal: expr})
+			break
+		}
+		if start > 0 {
+			parts = append(parts, templatePart{literal: expr[:start]})
+		}
+		expr = expr[start+2:]
+
+		end := strings.Index(expr, "}")
+		if end < 0 {
+			return nil, fmt.Errorf("Unterminated tag reference in template")
+		}
+		tags := strings.Split(expr[:end], "|")
+		for i := range tags {
+			tags[i] = strings.ToLower(strings.TrimSpace(tags[i]))
+			if len(tags[i]) == 0 {
+				return nil, fmt.Errorf("Empty tag name in template")
+			}
+		}
+		parts = append(parts, templatePart{tags: tags})
+		expr = expr[end+1:]
+	}
+	return parts, nil
+}
+
+// Value returns the value of the virtual tag for a specific song. If any of
+// the referenced tags are missing, or the regular expression does not match,
+// the song does not have this tag, and false is returned.
+func (v *VirtualTag) Value(s *Song) (string, bool) {
+	if v.regex != nil {
+		match := v.regex.FindStringSubmatch(s.StringTags[v.source])
+		switch {
+		case match == nil:
+			return "", false
+		case len(match) > 1:
+			return match[1], len(match[1]) > 0
+		default:
+			return match[0], len(match[0]) > 0
+		}
+	}
+
+	value := ""
+	for _, part := range v.template {
+		if part.tags == nil {
+			value += part.literal
+			continue
+		}
+		found := false
+		for _, tag := range part.tags {
+			if tagValue := s.StringTags[tag]; len(tagValue) > 0 {
+				value += tagValue
+				found = true
+				break
+			}
+		}
+		if !found {
+			return "", false
+		}
+	}
+
+	return value, len(value) > 0
+}
+
+// FillVirtualTags calculates the values of all virtual tags defined in
+// tagging. Virtual tags that have no value for this song are removed.
+func (s *Song) FillVirtualTags(tagging *Tagging) {
+	for _, v := range tagging.VirtualTags() {
+		value, ok := v.Value(s)
+		if !ok {
+			s.RemoveTag(v.Name)
+			continue
+		}
+		s.Tags[v.Name] = []rune(value)
+		s.StringTags[v.Name] = value
+	}
+}
+
+// RemoveTag removes a tag from the song.
+func (s *Song) RemoveTag(tag string) {
+	delete(s.Tags, tag)
+	delete(s.StringTags, tag)
+	delete(s.SortTags, tag)
+}
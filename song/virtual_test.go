@@ -0,0 +1,93 @@
+package song_test
+
+import (
+	"testing"
+
+	"github.com/ambientsound/gompd/mpd"
+	"github.com/ambientsound/pms/song"
+	"github.com/stretchr/testify/assert"
+)
+
+var virtualTagTests = []struct {
+	expr    string
+	success bool
+	tags    mpd.Attrs
+	value   string
+	ok      bool
+}{
+	// Templates
+	{`${albumartist|artist} – ${album}`, true, mpd.Attrs{"albumartist": "foo", "artist": "bar", "album": "baz"}, "foo – baz", true},
+	{`${albumartist|artist} – ${album}`, true, mpd.Attrs{"artist": "bar", "album": "baz"}, "bar – baz", true},
+	{`${albumartist|artist} – ${album}`, true, mpd.Attrs{"artist": "bar"}, "", false},
+	{`${ Title }!`, true, mpd.Attrs{"title": "foo"}, "foo!", true},
+	{`$5 for ${title}`, true, mpd.Attrs{"title": "foo"}, "$5 for foo", true},
+	{`${year}`, true, mpd.Attrs{"date": "1986-04-22"}, "1986", true},
+	{`constant`, true, mpd.Attrs{}, "constant", true},
+
+	// Regular expressions
+	{`file:regex(\.(\w+)$)`, true, mpd.Attrs{"file": "foo/bar.flac"}, "flac", true},
+	{`file:regex(^[^/]+)`, true, mpd.Attrs{"file": "foo/bar.flac"}, "foo", true},
+	{`file:regex(\.(\w+)$)`, true, mpd.Attrs{"file": "foo/bar"}, "", false},
+
+	// Invalid definitions
+	{`${album`, false, nil, "", false},
+	{`${}`, false, nil, "", false},
+	{`${artist|}`, false, nil, "", false},
+	{`file:regex(()`, false, nil, "", false},
+	{`${virtual}`, false, nil, "", false},
+	{`virtual:regex(.)`, false, nil, "", false},
+}
+
+func TestVirtualTag(t *testing.T) {
+	for n, test := range virtualTagTests {
+		t.Logf("### Test %d: '%s'", n+1, test.expr)
+
+		v, err := song.ParseVirtualTag("virtual", test.expr)
+		if !test.success {
+			assert.NotNil(t, err)
+			continue
+		}
+		assert.Nil(t, err)
+
+		s := song.New()
+		s.SetTags(test.tags)
+		value, ok := v.Value(s)
+		assert.Equal(t, test.value, value)
+		assert.Equal(t, test.ok, ok)
+	}
+}
+
+// Test that internal tags cannot be redefined.
+func TestVirtualTagReserved(t *testing.T) {
+	for _, tag := range []string{"file", "id", "pos", "prio", "time", "year", "Year"} {
+		_, err := song.ParseVirtualTag(tag, "${artist}")
+		assert.NotNil(t, err, tag)
+	}
+}
+
+// Test that virtual tags are filled in when song tags are set, that they can
+// refer to each other, and that they are used for sorting.
+func TestFillVirtualTags(t *testing.T) {
+	defs := []struct{ name, expr string }{
+		{"ext", `file:regex(\.(\w+)$)`},
+		{"format", `${ext} file`},
+	}
+	tagging := song.NewTagging()
+	for _, def := range defs {
+		v, err := song.ParseVirtualTag(def.name, def.expr)
+		assert.Nil(t, err)
+		tagging.Define(v)
+	}
+
+	s := song.New()
+	s.SetMultiTags(song.MultiTaglist{"file": {"foo/bar.FLAC"}}, tagging)
+	assert.Equal(t, "FLAC", s.StringTags["ext"])
+	assert.Equal(t, song.Tag("FLAC file"), s.Tags["format"])
+	assert.Equal(t, "flac file", s.SortTags["format"])
+
+	// Virtual tags without a value are removed.
+	s.StringTags["file"] = "foo/bar"
+	s.FillVirtualTags(tagging)
+	assert.NotContains(t, s.StringTags, "ext")
+	assert.NotContains(t, s.Tags, "format")
+}
@@ -53,6 +53,16 @@ func (c *Collection) Add(s Songlist) {
 	c.lists = append(c.lists, s)
 }
 
+// Contains returns true if the songlist is in the collection.
+func (c *Collection) Contains(s Songlist) bool {
+	for _, stored := range c.lists {
+		if stored == s {
+			return true
+		}
+	}
+	return false
+}
+
 // Current returns the active songlist.
 func (c *Collection) Current() Songlist {
 	return c.current
@@ -89,6 +99,26 @@ func (c *Collection) Remove(index int) error {
 	return nil
 }
 
+// Reorder rearranges the songlists, so that the songlist at index order[i]
+// is placed at index i.
+func (c *Collection) Reorder(order []int) error {
+	if err := validateOrder(c.Len(), order); err != nil {
+		return err
+	}
+	lists := make([]Songlist, len(order))
+	index := c.index
+	for i, j := range order {
+		lists[i] = c.lists[j]
+		if j == c.index {
+			index = i
+		}
+	}
+	c.lists = lists
+	c.index = index
+	c.SetUpdated()
+	return nil
+}
+
 // Replace replaces an existing songlist with its new version. Checking
 // is done on a type-level, so this function should not be used for lists where
 // several of the same type is contained within the collection.
@@ -114,6 +144,19 @@ func (c *Collection) Replace(s Songlist) {
 	c.Add(s)
 }
 
+// RefreshLists updates the lists views and message logs in the collection,
+// so that they show the current songlists and messages.
+func (c *Collection) RefreshLists() {
+	for _, list := range c.lists {
+		switch list := list.(type) {
+		case *Lists:
+			list.Refresh()
+		case *Log:
+			list.Refresh()
+		}
+	}
+}
+
 func (c *Collection) Songlist(index int) (Songlist, error) {
 	if err := c.ValidateIndex(index); err != nil {
 		return nil, err
@@ -1,17 +1,78 @@
 package songlist
 
 import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ambientsound/pms/options"
 	"github.com/ambientsound/pms/song"
 	"github.com/ambientsound/pms/utils"
 )
 
+// Columnar is implemented by songlists which do not contain music, and should
+// always be displayed using their own set of columns.
+type Columnar interface {
+	DefaultColumns() []string
+}
+
+// ColumnOption returns the column specification for a songlist. Lists of a
+// specific type use their own option if it is set, and fall back to the
+// 'columns' option otherwise.
+func ColumnOption(list Songlist, opts *options.Options) string {
+	if columnar, ok := list.(Columnar); ok {
+		return strings.Join(columnar.DefaultColumns(), ",")
+	}
+
+	key := ""
+	switch list.(type) {
+	case *Queue:
+		key = "queuecolumns"
+	case *Library:
+		key = "librarycolumns"
+	case *History:
+		key = "historycolumns"
+	}
+
+	if len(key) > 0 {
+		if spec := opts.StringValue(key); len(spec) > 0 {
+			return spec
+		}
+	}
+
+	return opts.StringValue("columns")
+}
+
 type Column struct {
 	tag        string
 	items      int
 	totalWidth int
 	maxWidth   int
+	widths     map[int]int
 	avg        int
 	width      int
+	spec       ColumnSpec
+}
+
+// ColumnSpec contains layout hints for a column, as given in the 'columns'
+// option. Zero values mean that the hint is not in effect.
+type ColumnSpec struct {
+	Tag        string
+	Width      int
+	Percent    int
+	MinWidth   int
+	MaxWidth   int
+	RightAlign bool
+}
+
+// rightAlignedTags are numeric tags which are right aligned by default.
+var rightAlignedTags = map[string]bool{
+	"disc":  true,
+	"id":    true,
+	"pos":   true,
+	"prio":  true,
+	"time":  true,
+	"track": true,
 }
 
 type Columns []*Column
@@ -20,7 +81,83 @@ type ColumnMap map[string]*Column
 
 // NewColumn returns a new Column.
 func NewColumn(tag string) *Column {
-	return &Column{tag: tag}
+	return &Column{
+		tag:    tag,
+		widths: make(map[int]int),
+		spec:   ColumnSpec{Tag: tag, RightAlign: rightAlignedTags[tag]},
+	}
+}
+
+// ParseColumnSpecs parses a comma-separated list of column specifications.
+// Each column is given as a tag name, optionally followed by one or more
+// colon-separated hints: a fixed width 'N', a percentage of the screen width
+// 'N%', a minimum width '>N', a maximum width '<N', or the alignment 'left'
+// or 'right'.
+func ParseColumnSpecs(s string) ([]ColumnSpec, error) {
+	specs := make([]ColumnSpec, 0)
+	for _, field := range strings.Split(s, ",") {
+		parts := strings.Split(strings.TrimSpace(field), ":")
+		tag := strings.ToLower(parts[0])
+		if len(tag) == 0 {
+			return nil, fmt.Errorf("Empty tag name in column specification '%s'", s)
+		}
+		spec := ColumnSpec{Tag: tag, RightAlign: rightAlignedTags[tag]}
+		for _, hint := range parts[1:] {
+			if err := spec.parseHint(hint); err != nil {
+				return nil, fmt.Errorf("Invalid hint '%s' for column '%s': %s", hint, tag, err)
+			}
+		}
+		specs = append(specs, spec)
+	}
+	return specs, nil
+}
+
+// parseHint applies a single layout hint to the column specification.
+func (spec *ColumnSpec) parseHint(hint string) error {
+	var err error
+	var n int
+
+	switch {
+	case hint == "left":
+		spec.RightAlign = false
+		return nil
+	case hint == "right":
+		spec.RightAlign = true
+		return nil
+	case strings.HasPrefix(hint, ">"):
+		n, err = strconv.Atoi(hint[1:])
+		spec.MinWidth = n
+	case strings.HasPrefix(hint, "<"):
+		n, err = strconv.Atoi(hint[1:])
+		spec.MaxWidth = n
+	case strings.HasSuffix(hint, "%"):
+		n, err = strconv.Atoi(hint[:len(hint)-1])
+		if err == nil && n > 100 {
+			return fmt.Errorf("percentage cannot exceed 100")
+		}
+		spec.Percent = n
+	default:
+		n, err = strconv.Atoi(hint)
+		spec.Width = n
+	}
+
+	if err != nil {
+		return fmt.Errorf("expected number, left, or right")
+	}
+	if n <= 0 {
+		return fmt.Errorf("width must be greater than zero")
+	}
+
+	return nil
+}
+
+// Tags returns the tag names of a list of column specifications.
+func Tags(specs []ColumnSpec) []string {
+	tags := make([]string, len(specs))
+	for i := range specs {
+		tags[i] = specs[i].Tag
+	}
+	return tags
 }
 
 // Set calculates all song's widths.
@@ -31,28 +168,41 @@ func (c *Column) Set(s Songlist) {
 	}
 }
 
-// Add a single song's width to the total and maximum width.
+// Add a single song's display width to the total and maximum width.
 func (c *Column) Add(song *song.Song) {
-	l := len(song.Tags[c.tag])
+	l := utils.RunesWidth(song.Tags[c.tag])
 	if l == 0 {
 		return
 	}
 	c.avg = 0
 	c.items++
 	c.totalWidth += l
+	c.widths[l]++
 	c.maxWidth = utils.Max(c.maxWidth, l)
 }
 
 // Remove a single song's tag width from the total and maximum width.
 func (c *Column) Remove(song *song.Song) {
-	l := len(song.Tags[c.tag])
+	l := utils.RunesWidth(song.Tags[c.tag])
 	if l == 0 {
 		return
 	}
 	c.avg = 0
 	c.items--
 	c.totalWidth -= l
-	// TODO: c.maxWidth is not updated
+	c.widths[l]--
+	if c.widths[l] > 0 {
+		return
+	}
+	delete(c.widths, l)
+
+	// The longest value was removed; find the next longest.
+	if l == c.maxWidth {
+		c.maxWidth = 0
+		for width := range c.widths {
+			c.maxWidth = utils.Max(c.maxWidth, width)
+		}
+	}
 }
 
 // Reset sets all values to zero.
@@ -60,6 +210,7 @@ func (c *Column) Reset() {
 	c.items = 0
 	c.totalWidth = 0
 	c.maxWidth = 0
+	c.widths = make(map[int]int)
 	c.avg = 0
 	c.width = 0
 }
@@ -71,7 +222,7 @@ func (c *Column) Weight(max int) float64 {
 	return float64(c.items) / float64(max)
 }
 
-// Avg returns the average length of the tag values in this column.
+// Avg returns the average display width of the tag values in this column.
 func (c *Column) Avg() int {
 	if c.avg == 0 {
 		if c.items == 0 {
@@ -89,7 +240,7 @@ func (c *Column) Tag() string {
 	return c.tag
 }
 
-// MaxWidth returns the length of the longest tag value in this column.
+// MaxWidth returns the display width of the widest tag value in this column.
 func (c *Column) MaxWidth() int {
 	return c.maxWidth
 }
@@ -104,41 +255,94 @@ func (c *Column) SetWidth(width int) {
 	c.width = width
 }
 
-// expand adjusts the column widths equally between the different columns,
-// giving affinity to weight.
+// SetSpec sets the layout hints for this column.
+func (c *Column) SetSpec(spec ColumnSpec) {
+	c.spec = spec
+}
+
+// RightAligned returns true if the column contents should be right aligned.
+func (c *Column) RightAligned() bool {
+	return c.spec.RightAlign
+}
+
+// fixedWidth returns the width of the column if the width is given by its
+// layout hints, or zero if the width is flexible.
+func (c *Column) fixedWidth(totalWidth int) int {
+	switch {
+	case c.spec.Width > 0:
+		return c.spec.Width
+	case c.spec.Percent > 0:
+		return utils.Max(1, totalWidth*c.spec.Percent/100)
+	default:
+		return 0
+	}
+}
+
+// clampWidth limits a width to the minimum and maximum width hints.
+func (c *Column) clampWidth(width int) int {
+	if c.spec.MaxWidth > 0 {
+		width = utils.Min(width, c.spec.MaxWidth)
+	}
+	return utils.Max(width, c.spec.MinWidth)
+}
+
+// Expand adjusts the column widths equally between the different columns,
+// giving affinity to weight. Columns with a fixed or percentage width are
+// given exactly that width, while the remaining columns are kept within their
+// minimum and maximum widths.
 func (columns Columns) Expand(totalWidth int) {
 	if len(columns) == 0 {
 		return
 	}
 
 	usedWidth := 0
-	poolSize := len(columns)
-	saturated := make([]bool, poolSize)
+	flexible := make(Columns, 0, len(columns))
 
-	// Start with the average value
-	for i := range columns {
-		avg := columns[i].Avg()
-		columns[i].SetWidth(avg)
-		usedWidth += avg
+	// Start with the fixed width, or the average value
+	for _, col := range columns {
+		width := col.fixedWidth(totalWidth)
+		if width == 0 {
+			width = col.clampWidth(col.Avg())
+			flexible = append(flexible, col)
+		}
+		col.SetWidth(width)
+		usedWidth += width
 	}
 
+	poolSize := len(flexible)
+	saturated := make([]bool, poolSize)
+
 	// expand as long as there is space left
 	for {
-		for i := range columns {
-			if usedWidth > totalWidth {
+		changed := false
+		for i, col := range flexible {
+			if usedWidth >= totalWidth {
 				return
 			}
+			if col.spec.MaxWidth > 0 && col.Width() >= col.spec.MaxWidth {
+				continue
+			}
 			if poolSize > 0 && saturated[i] {
 				continue
 			}
-			col := columns[i]
 			if poolSize > 0 && col.Width() > col.MaxWidth() {
 				saturated[i] = true
 				poolSize--
+				changed = true
 				continue
 			}
 			col.SetWidth(col.Width() + 1)
 			usedWidth++
+			changed = true
+		}
+
+		// If no column could grow, allow saturated columns to grow as well.
+		// Stop if none of them can grow.
+		if !changed {
+			if poolSize == 0 {
+				return
+			}
+			poolSize = 0
 		}
 	}
 }
@@ -153,7 +357,9 @@ func (c ColumnMap) Add(song *song.Song) {
 // Remove removes song tags from all applicable columns.
 func (c ColumnMap) Remove(song *song.Song) {
 	for tag := range song.StringTags {
-		c[tag].Remove(song)
+		if col, ok := c[tag]; ok {
+			col.Remove(song)
+		}
 	}
 }
 
@@ -166,6 +372,16 @@ func (s *BaseSonglist) ensureColumns(song *song.Song) {
 	}
 }
 
+// UpdateColumns recalculates all column widths. It must be called when the
+// tags of the songs in the list have changed.
+func (s *BaseSonglist) UpdateColumns() {
+	s.columns = make(ColumnMap)
+	for _, song := range s.songs {
+		s.ensureColumns(song)
+		s.columns.Add(song)
+	}
+}
+
 // Columns returns a slice of columns, containing only the columns which has
 // the specified tags.
 func (s *BaseSonglist) Columns(columns []string) Columns {
@@ -0,0 +1,131 @@
+package songlist_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ambientsound/gompd/mpd"
+	"github.com/ambientsound/pms/song"
+	"github.com/ambientsound/pms/songlist"
+	"github.com/stretchr/testify/assert"
+)
+
+var columnSpecTests = []struct {
+	input   string
+	success bool
+	specs   []songlist.ColumnSpec
+}{
+	{"artist,title", true, []songlist.ColumnSpec{
+		{Tag: "artist"},
+		{Tag: "title"},
+	}},
+	{"title:40%,time:5:left,artist:>20:<30,Track", true, []songlist.ColumnSpec{
+		{Tag: "title", Percent: 40},
+		{Tag: "time", Width: 5},
+		{Tag: "artist", MinWidth: 20, MaxWidth: 30},
+		{Tag: "track", RightAlign: true},
+	}},
+	{"year:4:right", true, []songlist.ColumnSpec{
+		{Tag: "year", Width: 4, RightAlign: true},
+	}},
+	{"", false, nil},
+	{"artist,,title", false, nil},
+	{"artist:", false, nil},
+	{"artist:0", false, nil},
+	{"artist:101%", false, nil},
+	{"artist:>x", false, nil},
+	{"artist:center", false, nil},
+}
+
+func TestParseColumnSpecs(t *testing.T) {
+	for _, test := range columnSpecTests {
+		specs, err := songlist.ParseColumnSpecs(test.input)
+		if test.success {
+			assert.Nil(t, err, test.input)
+		} else {
+			assert.NotNil(t, err, test.input)
+		}
+		assert.Equal(t, test.specs, specs, test.input)
+	}
+}
+
+// columnTestList returns a songlist where the artist tag has the given lengths.
+func columnTestList(lengths ...int) songlist.Songlist {
+	list := songlist.New()
+	for _, l := range lengths {
+		s := song.New()
+		s.SetTags(mpd.Attrs{
+			"artist": strings.Repeat("x", l),
+			"title":  strings.Repeat("y", 10),
+		})
+		list.Add(s)
+	}
+	return list
+}
+
+func TestColumnMaxWidthAfterRemove(t *testing.T) {
+	list := columnTestList(5, 20, 20, 10)
+	col := list.Columns([]string{"artist"})[0]
+	assert.Equal(t, 20, col.MaxWidth())
+
+	list.Remove(1)
+	assert.Equal(t, 20, col.MaxWidth())
+
+	list.Remove(1)
+	assert.Equal(t, 10, col.MaxWidth())
+
+	list.Remove(1)
+	assert.Equal(t, 5, col.MaxWidth())
+}
+
+// Test that column widths are measured in terminal cells, so that wide
+// characters count twice, and combining characters are not counted.
+func TestColumnDisplayWidth(t *testing.T) {
+	list := songlist.New()
+	for _, artist := range []string{"坂本龍一", "Bjo\u0308rk"} {
+		s := song.New()
+		s.SetTags(mpd.Attrs{"artist": artist})
+		list.Add(s)
+	}
+	col := list.Columns([]string{"artist"})[0]
+	assert.Equal(t, 8, col.MaxWidth())
+	assert.Equal(t, 6, col.Avg())
+}
+
+func expandColumns(t *testing.T, spec string, width int) songlist.Columns {
+	list := columnTestList(10, 20, 30)
+	specs, err := songlist.ParseColumnSpecs(spec)
+	assert.Nil(t, err)
+	cols := list.Columns(songlist.Tags(specs))
+	for i := range cols {
+		cols[i].SetSpec(specs[i])
+	}
+	cols.Expand(width)
+	return cols
+}
+
+func TestColumnsExpand(t *testing.T) {
+	// Fixed and percentage widths are kept.
+	cols := expandColumns(t, "artist:50%,title:8", 100)
+	assert.Equal(t, 50, cols[0].Width())
+	assert.Equal(t, 8, cols[1].Width())
+
+	// Maximum widths are never exceeded, and minimum widths are respected.
+	cols = expandColumns(t, "artist:<15,title:>40", 100)
+	assert.Equal(t, 15, cols[0].Width())
+	assert.True(t, cols[1].Width() >= 40)
+	assert.Equal(t, 100, cols[0].Width()+cols[1].Width())
+
+	// Flexible columns fill the remaining space.
+	cols = expandColumns(t, "artist,title:10", 100)
+	assert.Equal(t, 10, cols[1].Width())
+	assert.Equal(t, 100, cols[0].Width()+cols[1].Width())
+
+	cols = expandColumns(t, "artist,title,album", 100)
+	assert.Equal(t, 100, cols[0].Width()+cols[1].Width()+cols[2].Width())
+
+	// Right alignment.
+	cols = expandColumns(t, "artist:right,title", 100)
+	assert.True(t, cols[0].RightAligned())
+	assert.False(t, cols[1].RightAligned())
+}
@@ -0,0 +1,113 @@
+package songlist
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ambientsound/pms/song"
+)
+
+// versionWords are words which, when found in a parenthesized or dashed
+// suffix, denote a different release of the same recording.
+const versionWords = `remaster|remastered|deluxe|expanded|edition|bonus track|explicit|mono|stereo|album version|single version`
+
+var versionSuffixes = []*regexp.Regexp{
+	regexp.MustCompile(`\s*[(\[][^()\[\]]*\b(` + versionWords + `)\b[^()\[\]]*[)\]]$`),
+	regexp.MustCompile(`\s+-\s+[^-]*\b(` + versionWords + `)\b[^-]*$`),
+}
+
+var whitespace = regexp.MustCompile(`\s+`)
+
+// NormalizeTag returns a tag value suitable for finding duplicates. The value
+// is case folded, whitespace is collapsed, and suffixes such as
+// "(Remastered)" or " - 2011 Remaster" are removed.
+func NormalizeTag(value string) string {
+	value = whitespace.ReplaceAllString(strings.ToLower(value), " ")
+	value = strings.TrimSpace(value)
+	for {
+		stripped := value
+		for _, re := range versionSuffixes {
+			stripped = re.ReplaceAllString(stripped, "")
+		}
+		if stripped == value || len(stripped) == 0 {
+			return value
+		}
+		value = stripped
+	}
+}
+
+// Duplicates finds songs which have the same normalized values for all of
+// the given tags, and returns them in a new songlist, where duplicates are
+// placed next to each other. Songs missing any of the tags are ignored. If
+// maxTimeDiff is positive, duplicates must also have durations within
+// maxTimeDiff seconds of each other.
+func Duplicates(songs []*song.Song, tags []string, maxTimeDiff int) Songlist {
+	groups := make(map[string][]*song.Song)
+	keys := make([]string, 0)
+
+	for _, s := range songs {
+		values := make([]string, len(tags))
+		for i, tag := range tags {
+			values[i] = NormalizeTag(s.StringTags[tag])
+			if len(values[i]) == 0 {
+				values = nil
+				break
+			}
+		}
+		if values == nil {
+			continue
+		}
+		key := strings.Join(values, "\x00")
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], s)
+	}
+
+	sort.Strings(keys)
+
+	list := NewSearch()
+	for _, key := range keys {
+		for _, group := range splitByTime(groups[key], maxTimeDiff) {
+			if len(group) < 2 {
+				continue
+			}
+			for _, s := range group {
+				list.Add(s)
+			}
+		}
+	}
+
+	list.SetName("Duplicates: " + strings.Join(tags, ", "))
+
+	return list
+}
+
+// splitByTime splits a group of songs into smaller groups, where each song has
+// a duration within maxTimeDiff seconds of the shortest song in its group.
+// Songs are not chained together, so no two songs in a group differ by more
+// than maxTimeDiff seconds. If maxTimeDiff is zero or negative,
+// the group is not split.
+func splitByTime(songs []*song.Song, maxTimeDiff int) [][]*song.Song {
+	if maxTimeDiff <= 0 {
+		return [][]*song.Song{songs}
+	}
+
+	sorted := make([]*song.Song, len(songs))
+	copy(sorted, songs)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Time < sorted[j].Time
+	})
+
+	groups := make([][]*song.Song, 0)
+	start := 0
+	for i := 1; i <= len(sorted); i++ {
+		if i == len(sorted) || sorted[i].Time-sorted[start].Time > maxTimeDiff {
+			groups = append(groups, sorted[start:i])
+			start = i
+		}
+	}
+
+	return groups
+}
@@ -0,0 +1,95 @@
+package songlist_test
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/ambientsound/gompd/mpd"
+	"github.com/ambientsound/pms/song"
+	"github.com/ambientsound/pms/songlist"
+	"github.com/stretchr/testify/assert"
+)
+
+var normalizeTagTests = []struct {
+	input  string
+	output string
+}{
+	{"Foo Bar", "foo bar"},
+	{"  Foo   Bar ", "foo bar"},
+	{"Foo (Remastered)", "foo"},
+	{"Foo [2011 Remaster]", "foo"},
+	{"Foo - Remastered 2009", "foo"},
+	{"Foo (Live) (Deluxe Edition)", "foo (live)"},
+	{"Foo (Live)", "foo (live)"},
+	{"Foo - Bar", "foo - bar"},
+	{"(Remastered)", "(remastered)"},
+}
+
+func TestNormalizeTag(t *testing.T) {
+	for _, test := range normalizeTagTests {
+		assert.Equal(t, test.output, songlist.NormalizeTag(test.input), test.input)
+	}
+}
+
+func duplicateSongs() []*song.Song {
+	songs := make([]*song.Song, 0)
+	for i, tags := range []mpd.Attrs{
+		{"artist": "Foo", "title": "Bar", "time": "200"},
+		{"artist": "Baz", "title": "Qux", "time": "100"},
+		{"artist": "foo", "title": "Bar (Remastered)", "time": "203"},
+		{"artist": "Foo", "title": "Bar", "time": "300"},
+		{"artist": "Baz", "title": "Other", "time": "100"},
+		{"title": "Qux", "time": "100"},
+	} {
+		s := song.New()
+		tags["file"] = strconv.Itoa(i)
+		s.SetTags(tags)
+		songs = append(songs, s)
+	}
+	return songs
+}
+
+func files(list songlist.Songlist) []string {
+	files := make([]string, list.Len())
+	for i, s := range list.Songs() {
+		files[i] = s.StringTags["file"]
+	}
+	return files
+}
+
+func TestDuplicates(t *testing.T) {
+	songs := duplicateSongs()
+
+	list := songlist.Duplicates(songs, []string{"artist", "title"}, 0)
+	assert.Equal(t, []string{"0", "2", "3"}, files(list))
+	assert.Equal(t, "Duplicates: artist, title", list.Name())
+
+	list = songlist.Duplicates(songs, []string{"artist", "title"}, 5)
+	assert.Equal(t, []string{"0", "2"}, files(list))
+
+	list = songlist.Duplicates(songs, []string{"title"}, 0)
+	assert.Equal(t, []string{"0", "2", "3", "1", "5"}, files(list))
+
+	list = songlist.Duplicates(songs, []string{"musicbrainz_trackid"}, 0)
+	assert.Equal(t, 0, list.Len())
+}
+
+// Test that songs are grouped by their time difference to the shortest song in
+// the group, and not chained together by the difference to the previous song.
+func TestDuplicatesTimeChain(t *testing.T) {
+	songs := make([]*song.Song, 0)
+	for i, length := range []string{"208", "200", "204"} {
+		s := song.New()
+		s.SetTags(mpd.Attrs{"file": strconv.Itoa(i), "title": "Foo", "time": length})
+		songs = append(songs, s)
+	}
+
+	list := songlist.Duplicates(songs, []string{"title"}, 8)
+	assert.Equal(t, []string{"1", "2", "0"}, files(list))
+
+	list = songlist.Duplicates(songs, []string{"title"}, 5)
+	assert.Equal(t, []string{"1", "2"}, files(list))
+
+	list = songlist.Duplicates(songs, []string{"title"}, 3)
+	assert.Equal(t, 0, list.Len())
+}
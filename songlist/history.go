@@ -0,0 +1,234 @@
+package songlist
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"time"
+
+	"github.com/ambientsound/pms/console"
+	pms_mpd "github.com/ambientsound/pms/mpd"
+	"github.com/ambientsound/pms/song"
+)
+
+// HistoryTimeFormat is the format of the 'played' tag in history songs.
+const HistoryTimeFormat = "2006-01-02 15:04:05"
+
+// historyDateFormat is the date part of HistoryTimeFormat.
+const historyDateFormat = "2006-01-02"
+
+// historyEntry is a single line in the history file.
+type historyEntry struct {
+	Time time.Time   `json:"time"`
+	Tags historyTags `json:"tags"`
+}
+
+// historyTags holds every value of each tag in a history entry.
+type historyTags map[string][]string
+
+// UnmarshalJSON implements json.Unmarshaler. Tag values are lists of strings,
+// but single strings are also accepted, as written by earlier versions.
+func (t *historyTags) UnmarshalJSON(data []byte) error {
+	raw := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*t = make(historyTags, len(raw))
+	for key, value := range raw {
+		values := make([]string, 0, 1)
+		if err := json.Unmarshal(value, &values); err != nil {
+			single := ""
+			if err = json.Unmarshal(value, &single); err != nil {
+				return err
+			}
+			values = append(values, single)
+		}
+		(*t)[key] = values
+	}
+	return nil
+}
+
+// HistoryTracker keeps track of whether the currently playing song has
+// already been recorded in the listening history.
+type HistoryTracker struct {
+	songID   int
+	recorded bool
+}
+
+// History is a Songlist which represents songs that have been played, with
+// the most recently played song last. Every song is also written to an
+// append-only history file.
+type History struct {
+	BaseSonglist
+	path    string
+	tagging *song.Tagging
+	daily   map[string]int
+}
+
+// NewHistory returns History, which records songs in the file at the given
+// path. The tags of songs read from the file are calculated using tagging.
+func NewHistory(path string, tagging *song.Tagging) (s *History) {
+	s = &History{
+		path:    path,
+		tagging: tagging,
+		daily:   make(map[string]int),
+	}
+	s.clear()
+	return
+}
+
+func (s *History) Name() string {
+	return "History"
+}
+
+func (s *History) SetName(name string) error {
+	return fmt.Errorf("The history name cannot be changed.")
+}
+
+func (s *History) Clear() error {
+	return fmt.Errorf("The history cannot be cleared because it is read-only.")
+}
+
+func (s *History) Delete() error {
+	return fmt.Errorf("The history cannot be removed.")
+}
+
+func (s *History) Sort(fields []string) error {
+	return fmt.Errorf("The history is read-only. Please make a copy if you want to sort.")
+}
+
+func (s *History) Remove(index int) error {
+	return fmt.Errorf("The history is read-only.")
+}
+
+func (s *History) RemoveIndices(indices []int) error {
+	return fmt.Errorf("The history is read-only.")
+}
+
+func (s *History) Reorder(order []int) error {
+	return fmt.Errorf("The history is read-only. Please make a copy if you want to reorder songs.")
+}
+
+// Load reads all songs from the history file. A missing history file is not
+// considered an error.
+func (s *History) Load() error {
+	file, err := os.Open(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		entry := historyEntry{}
+		if err = json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			console.Log("Ignoring corrupt line in history file %s: %s", s.path, err)
+			continue
+		}
+		s.addEntry(entry)
+	}
+
+	console.Log("Loaded %d songs from history file %s", s.Len(), s.path)
+
+	return scanner.Err()
+}
+
+// Record appends a song to the history file and the songlist.
+func (s *History) Record(played *song.Song, t time.Time) error {
+	entry := historyEntry{
+		Time: t,
+		Tags: make(historyTags),
+	}
+
+	// Only the tags from MPD are recorded; virtual and derived tags are
+	// filled in again when the history is loaded. Queue-specific tags are not
+	// carried over, so that adding history songs to the queue will always
+	// add them as new songs.
+	for key, values := range played.MultiTags {
+		switch key {
+		case "id", "pos", "prio", "played":
+			continue
+		}
+		entry.Tags[key] = values
+	}
+
+	if err := os.MkdirAll(path.Dir(s.path), os.ModeDir|0755); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+	if _, err = file.Write(line); err != nil {
+		return err
+	}
+
+	s.addEntry(entry)
+	s.SetUpdated()
+
+	return nil
+}
+
+// PlayedOn returns the number of songs played on the same day as t.
+func (s *History) PlayedOn(t time.Time) int {
+	return s.daily[t.Local().Format(historyDateFormat)]
+}
+
+// Due returns true if the current song should be recorded in the listening
+// history, which is when it has been playing for longer than threshold
+// seconds, and has not been recorded already. A song is due again if it is
+// restarted from the beginning. A threshold of zero or less disables
+// recording.
+func (t *HistoryTracker) Due(status pms_mpd.PlayerStatus, current *song.Song, threshold int) bool {
+	if status.SongID != t.songID || status.Elapsed < 1.0 {
+		t.songID = status.SongID
+		t.recorded = false
+	}
+
+	if t.recorded || threshold <= 0 || status.State != pms_mpd.StatePlay {
+		return false
+	}
+	if status.Elapsed < float64(threshold) {
+		return false
+	}
+	if current == nil || current.ID != status.SongID {
+		return false
+	}
+
+	t.recorded = true
+	return true
+}
+
+// addEntry adds a history file entry to the songlist, and counts it towards
+// the number of songs played that day.
+func (s *History) addEntry(entry historyEntry) {
+	s.add(s.historySong(entry))
+	s.daily[entry.Time.Local().Format(historyDateFormat)]++
+}
+
+// historySong creates a song from a history file entry. The time of play is
+// available in the 'played' tag.
+func (s *History) historySong(entry historyEntry) *song.Song {
+	tags := make(song.MultiTaglist, len(entry.Tags)+1)
+	for key, values := range entry.Tags {
+		tags[key] = values
+	}
+	tags["played"] = []string{entry.Time.Local().Format(HistoryTimeFormat)}
+	played := song.New()
+	played.SetMultiTags(tags, s.tagging)
+	return played
+}
@@ -0,0 +1,204 @@
+package songlist_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ambientsound/gompd/mpd"
+	pms_mpd "github.com/ambientsound/pms/mpd"
+	"github.com/ambientsound/pms/song"
+	"github.com/ambientsound/pms/songlist"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test that recorded songs are written to the history file without any
+// queue-specific tags, and read back when loading the history.
+func TestHistoryRecordLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pms-history")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "data", "history")
+	history := songlist.NewHistory(path, nil)
+
+	// A missing history file is not an error.
+	assert.Nil(t, history.Load())
+	assert.Equal(t, 0, history.Len())
+
+	played := song.New()
+	played.SetTags(mpd.Attrs{
+		"file":   "foo.flac",
+		"artist": "foo",
+		"id":     "12",
+		"pos":    "3",
+		"prio":   "100",
+	})
+
+	first := time.Date(2020, 5, 1, 23, 59, 0, 0, time.Local)
+	second := time.Date(2020, 5, 2, 0, 1, 0, 0, time.Local)
+	assert.Nil(t, history.Record(played, first))
+	assert.Nil(t, history.Record(played, second))
+	assert.Equal(t, 2, history.Len())
+
+	loaded := songlist.NewHistory(path, nil)
+	assert.Nil(t, loaded.Load())
+	require.Equal(t, 2, loaded.Len())
+
+	s := loaded.Song(0)
+	assert.Equal(t, "foo.flac", s.StringTags["file"])
+	assert.Equal(t, "foo", s.StringTags["artist"])
+	assert.Equal(t, "2020-05-01 23:59:00", s.StringTags["played"])
+	assert.True(t, s.NullID())
+	for _, tag := range []string{"id", "pos", "prio"} {
+		_, ok := s.StringTags[tag]
+		assert.False(t, ok, "tag '%s' should not be recorded", tag)
+	}
+
+	// History songs can be recorded again, with a new time of play.
+	assert.Nil(t, loaded.Record(loaded.Song(0), second))
+	assert.Equal(t, "2020-05-02 00:01:00", loaded.Song(2).StringTags["played"])
+}
+
+// Test that corrupt lines in the history file are skipped.
+func TestHistoryLoadCorrupt(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pms-history")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "history")
+	content := `{"time":"2020-05-01T12:00:00Z","tags":{"file":"a.flac"}}
+{"time":"2020-05-01T12:05:00Z","tags":
+not json at all
+
+{"time":"2020-05-01T12:10:00Z","tags":{"file":"b.flac"}}
+`
+	require.Nil(t, ioutil.WriteFile(path, []byte(content), 0644))
+
+	history := songlist.NewHistory(path, nil)
+	assert.Nil(t, history.Load())
+	require.Equal(t, 2, history.Len())
+	assert.Equal(t, "a.flac", history.Song(0).StringTags["file"])
+	assert.Equal(t, "b.flac", history.Song(1).StringTags["file"])
+}
+
+// Test that songs are counted by the day they were played.
+func TestHistoryPlayedOn(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pms-history")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	history := songlist.NewHistory(filepath.Join(dir, "history"), nil)
+	played := song.New()
+	played.SetTags(mpd.Attrs{"file": "foo.flac"})
+
+	for _, t0 := range []time.Time{
+		time.Date(2020, 5, 1, 0, 0, 0, 0, time.Local),
+		time.Date(2020, 5, 1, 23, 59, 59, 0, time.Local),
+		time.Date(2020, 5, 2, 0, 0, 0, 0, time.Local),
+	} {
+		require.Nil(t, history.Record(played, t0))
+	}
+
+	assert.Equal(t, 2, history.PlayedOn(time.Date(2020, 5, 1, 12, 0, 0, 0, time.Local)))
+	assert.Equal(t, 1, history.PlayedOn(time.Date(2020, 5, 2, 12, 0, 0, 0, time.Local)))
+	assert.Equal(t, 0, history.PlayedOn(time.Date(2020, 5, 3, 12, 0, 0, 0, time.Local)))
+
+	loaded := songlist.NewHistory(filepath.Join(dir, "history"), nil)
+	require.Nil(t, loaded.Load())
+	assert.Equal(t, 2, loaded.PlayedOn(time.Date(2020, 5, 1, 12, 0, 0, 0, time.Local)))
+	assert.Equal(t, 1, loaded.PlayedOn(time.Date(2020, 5, 2, 12, 0, 0, 0, time.Local)))
+}
+
+// Test that songs are due for recording once, after playing for longer than
+// the threshold, and again when restarted from the beginning.
+func TestHistoryTracker(t *testing.T) {
+	current := song.New()
+	current.SetTags(mpd.Attrs{"file": "foo.flac", "id": "7"})
+	other := song.New()
+	other.SetTags(mpd.Attrs{"file": "bar.flac", "id": "8"})
+
+	status := func(id int, elapsed float64, state string) pms_mpd.PlayerStatus {
+		return pms_mpd.PlayerStatus{SongID: id, Elapsed: elapsed, State: state}
+	}
+
+	tests := []struct {
+		status    pms_mpd.PlayerStatus
+		current   *song.Song
+		threshold int
+		due       bool
+	}{
+		{status(7, 0, pms_mpd.StatePlay), current, 30, false},
+		{status(7, 29.5, pms_mpd.StatePlay), current, 30, false},
+		{status(7, 30, pms_mpd.StatePlay), current, 30, true},
+		// Recorded only once.
+		{status(7, 31, pms_mpd.StatePlay), current, 30, false},
+		// Restarting the song makes it due again.
+		{status(7, 0.5, pms_mpd.StatePlay), current, 30, false},
+		{status(7, 40, pms_mpd.StatePlay), current, 30, true},
+		// Paused songs are not recorded.
+		{status(8, 40, pms_mpd.StatePause), other, 30, false},
+		// The current song must match the player status.
+		{status(8, 40, pms_mpd.StatePlay), current, 30, false},
+		{status(8, 40, pms_mpd.StatePlay), nil, 30, false},
+		{status(8, 41, pms_mpd.StatePlay), other, 30, true},
+		// A threshold of zero disables recording.
+		{status(7, 0, pms_mpd.StatePlay), current, 0, false},
+		{status(7, 100, pms_mpd.StatePlay), current, 0, false},
+	}
+
+	tracker := &songlist.HistoryTracker{}
+	for i, test := range tests {
+		due := tracker.Due(test.status, test.current, test.threshold)
+		assert.Equal(t, test.due, due, "test %d", i+1)
+	}
+}
+
+// Test that all values of multi-value tags are recorded, while derived and
+// virtual tags are not, and that history files with single tag values are
+// still read.
+func TestHistoryMultiTags(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pms-history")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	tagging := song.NewTagging()
+	v, err := song.ParseVirtualTag("virtual", `${artist}!`)
+	require.Nil(t, err)
+	tagging.Define(v)
+
+	path := filepath.Join(dir, "history")
+	history := songlist.NewHistory(path, tagging)
+
+	played := song.New()
+	played.SetMultiTags(song.MultiTaglist{
+		"file":   {"foo.flac"},
+		"artist": {"foo", "bar"},
+		"date":   {"2001-02-03"},
+	}, tagging)
+	require.Nil(t, history.Record(played, time.Date(2020, 5, 1, 12, 0, 0, 0, time.UTC)))
+
+	content, err := ioutil.ReadFile(path)
+	require.Nil(t, err)
+	assert.Contains(t, string(content), `"artist":["foo","bar"]`)
+	assert.NotContains(t, string(content), `"year"`)
+	assert.NotContains(t, string(content), `"virtual"`)
+
+	legacy := `{"time":"2020-05-01T12:05:00Z","tags":{"file":"bar.flac","artist":"baz"}}` + "\n"
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	require.Nil(t, err)
+	_, err = file.WriteString(legacy)
+	require.Nil(t, err)
+	file.Close()
+
+	loaded := songlist.NewHistory(path, tagging)
+	assert.Nil(t, loaded.Load())
+	require.Equal(t, 2, loaded.Len())
+	assert.Equal(t, []string{"foo", "bar"}, loaded.Song(0).Values("artist"))
+	assert.Equal(t, "foo; bar!", loaded.Song(0).StringTags["virtual"])
+	assert.Equal(t, "2001", loaded.Song(0).StringTags["year"])
+	assert.Equal(t, []string{"baz"}, loaded.Song(1).Values("artist"))
+}
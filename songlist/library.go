@@ -3,25 +3,37 @@ package songlist
 import (
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/ambientsound/pms/console"
 	"github.com/ambientsound/pms/index"
+	index_song "github.com/ambientsound/pms/index/song"
+	"github.com/ambientsound/pms/song"
+	"github.com/ambientsound/pms/utils"
 	"github.com/blevesearch/bleve"
+	"github.com/blevesearch/bleve/search/query"
 )
 
+// matchCandidates is the number of search results considered by Match.
+const matchCandidates = 10
+
+// matchMaxTimeDiff is the largest difference in seconds between the length of
+// a song and the duration given to Match.
+const matchMaxTimeDiff = 2
+
 // Library is a Songlist which represents the MPD song library.
 type Library struct {
 	BaseSonglist
 	index           *index.Index
 	version         int
 	shutdownReIndex chan int
+	reIndexDone     chan struct{}
+	reIndexing      sync.WaitGroup
 }
 
 func NewLibrary() (s *Library) {
-	s = &Library{
-		shutdownReIndex: make(chan int, 1),
-	}
+	s = &Library{}
 	s.clear()
 	return
 }
@@ -54,8 +66,14 @@ func (s *Library) RemoveIndices(indices []int) error {
 	return fmt.Errorf("The song library is read-only.")
 }
 
-// OpenIndex configures the library to use the Bleve search index at the specified path.
-func (s *Library) OpenIndex(path string) error {
+func (s *Library) Reorder(order []int) error {
+	return fmt.Errorf("The song library is read-only. Please make a copy if you want to reorder songs.")
+}
+
+// OpenIndex configures the library to use the Bleve search index at the
+// specified path. If timeout is positive, opening the index fails if it is
+// locked by another process for longer than that.
+func (s *Library) OpenIndex(path string, timeout time.Duration) error {
 	var err error
 
 	if s.HasIndex() {
@@ -65,7 +83,7 @@ func (s *Library) OpenIndex(path string) error {
 		s.index = nil
 	}
 
-	s.index, err = index.New(path)
+	s.index, err = index.New(path, timeout)
 
 	return err
 }
@@ -102,11 +120,19 @@ func (s *Library) Version() int {
 // called again before reindexing is done, ReIndex will abort the old
 // reindexing job.
 func (s *Library) ReIndex() {
-	s.shutdownReIndex <- 0
-	s.shutdownReIndex = make(chan int, 1)
+	s.CancelReIndex()
+
+	shutdown := make(chan int, 1)
+	done := make(chan struct{})
+	s.shutdownReIndex = shutdown
+	s.reIndexDone = done
+
+	s.reIndexing.Add(1)
 	go func() {
+		defer s.reIndexing.Done()
+		defer close(done)
 		timer := time.Now()
-		err := s.index.IndexFull(s.Songs(), s.shutdownReIndex)
+		err := s.index.IndexFull(s.Songs(), shutdown)
 		console.Log("Song library index complete, took %s", time.Since(timer).String())
 
 		if err != nil {
@@ -117,6 +143,28 @@ func (s *Library) ReIndex() {
 	}()
 }
 
+// CancelReIndex aborts a running reindexing job, and waits for it to stop.
+// It returns true if a job was aborted, in which case the index is left out
+// of date, and ReIndex should be called again.
+func (s *Library) CancelReIndex() bool {
+	if s.reIndexDone == nil {
+		return false
+	}
+	select {
+	case <-s.reIndexDone:
+		return false
+	default:
+	}
+	s.shutdownReIndex <- 0
+	<-s.reIndexDone
+	return true
+}
+
+// WaitReIndex blocks until any running reindexing jobs are finished.
+func (s *Library) WaitReIndex() {
+	s.reIndexing.Wait()
+}
+
 // Search does a search in the Bleve index for a specific natural language
 // query string, and returns a new Songlist with the search results.
 func (s *Library) Search(q string) (Songlist, error) {
@@ -133,7 +181,7 @@ func (s *Library) Search(q string) (Songlist, error) {
 		return nil, err
 	}
 
-	list := New()
+	list := NewSearch()
 	list.SetName(q)
 
 	for _, id := range ids {
@@ -147,11 +195,76 @@ func (s *Library) Search(q string) (Songlist, error) {
 	return list, nil
 }
 
+// Files returns a map of all songs in the library, keyed by file name.
+func (s *Library) Files() map[string]*song.Song {
+	files := make(map[string]*song.Song, s.Len())
+	for _, song := range s.Songs() {
+		files[song.StringTags["file"]] = song
+	}
+	return files
+}
+
+// Match uses fuzzy matching in the search index to find the song that best
+// matches the given tag values. Every word of every tag value must match,
+// allowing for a small spelling difference in each word, and results scoring
+// below the search threshold are discarded. If the duration in seconds is
+// known, songs whose length differs by more than matchMaxTimeDiff are also
+// discarded; otherwise, it should be -1. Tags that are not in the search index
+// are ignored. If no song matches, nil is returned.
+func (s *Library) Match(tags map[string]string, duration int) (*song.Song, error) {
+	if !s.HasIndex() {
+		return nil, fmt.Errorf("Search index is not open.")
+	}
+
+	conjunction := bleve.NewConjunctionQuery()
+	for tag, value := range tags {
+		if len(value) == 0 || !index_song.Indexed(tag) {
+			continue
+		}
+		subQuery := bleve.NewMatchQuery(value)
+		subQuery.SetField(strings.Title(tag))
+		subQuery.SetFuzziness(1)
+		subQuery.SetOperator(query.MatchQueryOperatorAnd)
+		conjunction.AddQuery(subQuery)
+	}
+	if len(conjunction.Conjuncts) == 0 {
+		return nil, nil
+	}
+
+	request := bleve.NewSearchRequest(conjunction)
+	request.Size = matchCandidates
+	r, _, err := s.index.Query(request)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, i := range r {
+		candidate := s.Song(i)
+		if candidate == nil {
+			return nil, fmt.Errorf("Search index is corrupt.")
+		}
+		if duration >= 0 && candidate.Time > 0 && utils.Abs(candidate.Time-duration) > matchMaxTimeDiff {
+			continue
+		}
+		return candidate, nil
+	}
+
+	return nil, nil
+}
+
 // Isolate takes a songlist and a set of tag keys, and matches the tag values
-// of the songlist against the search index.
+// of the songlist against the search index. If any of the tags are not in the
+// search index, such as virtual tags, or if the search index is not open, the
+// tag values are compared directly.
 func (s *Library) Isolate(songs Songlist, tags []string) (Songlist, error) {
 	if !s.HasIndex() {
-		return nil, fmt.Errorf("Search index is not open.")
+		return s.isolateTags(songs, tags), nil
+	}
+
+	for _, tag := range tags {
+		if !index_song.Indexed(tag) {
+			return s.isolateTags(songs, tags), nil
+		}
 	}
 
 	terms := make(map[string]struct{})
@@ -164,36 +277,95 @@ func (s *Library) Isolate(songs Songlist, tags []string) (Songlist, error) {
 		for _, tag := range tags {
 
 			// Ignore empty values
-			tagValue := song.StringTags[tag]
-			if len(tagValue) == 0 {
+			values := song.Values(tag)
+			if len(values) == 0 {
 				continue
 			}
 
-			// Name generation
-			terms[tagValue] = struct{}{}
-
+			// Match any of the tag values.
 			field := strings.Title(tag)
-			query := bleve.NewMatchPhraseQuery(tagValue)
-			query.SetField(field)
-			subQuery.AddQuery(query)
+			valueQuery := bleve.NewDisjunctionQuery()
+			for _, value := range values {
+				// Name generation
+				terms[value] = struct{}{}
+
+				query := bleve.NewMatchPhraseQuery(value)
+				query.SetField(field)
+				valueQuery.AddQuery(query)
+			}
+			subQuery.AddQuery(valueQuery)
 		}
 		query.AddShould(subQuery)
 	}
 
-	// Construct a fitting name for this track list
-	names := make([]string, 0)
-	for k := range terms {
-		names = append(names, k)
-	}
-	name := strings.Join(names, ", ")
-
 	// Make the search
 	request := bleve.NewSearchRequest(query)
 	request.Size = s.Len()
 	r, _, err := s.index.Query(request)
-	list := s.Indices(r)
+	list := NewSearch()
+	list.AddList(s.Indices(r))
 
-	list.SetName(name)
+	list.SetName(isolateName(terms))
 
 	return list, err
 }
+
+// isolateTags finds all songs in the library having the same tag values as
+// any of the songs in the songlist, without using the search index. As with
+// the search index, empty tag values are ignored, and tags with several values
+// match any of the values.
+func (s *Library) isolateTags(songs Songlist, tags []string) Songlist {
+	terms := make(map[string]struct{})
+	patterns := make([]map[string][]string, 0, songs.Len())
+
+	for _, song := range songs.Songs() {
+		pattern := make(map[string][]string)
+		for _, tag := range tags {
+			values := song.Values(tag)
+			if len(values) == 0 {
+				continue
+			}
+			for _, value := range values {
+				terms[value] = struct{}{}
+			}
+			pattern[tag] = values
+		}
+		if len(pattern) > 0 {
+			patterns = append(patterns, pattern)
+		}
+	}
+
+	list := NewSearch()
+	for _, song := range s.Songs() {
+		for _, pattern := range patterns {
+			if matchPattern(song, pattern) {
+				list.Add(song)
+				break
+			}
+		}
+	}
+
+	list.SetName(isolateName(terms))
+
+	return list
+}
+
+// matchPattern returns true if the song has at least one of the given values
+// for each tag.
+func matchPattern(s *song.Song, pattern map[string][]string) bool {
+	for tag, values := range pattern {
+		if !s.HasOneOfValues(tag, values) {
+			return false
+		}
+	}
+	return true
+}
+
+// isolateName constructs a fitting name for an isolated track list.
+func isolateName(terms map[string]struct{}) string {
+	names := make([]string, 0, len(terms))
+	for k := range terms {
+		names = append(names, k)
+	}
+	return strings.Join(names, ", ")
+}
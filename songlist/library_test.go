@@ -0,0 +1,129 @@
+package songlist_test
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/ambientsound/gompd/mpd"
+	"github.com/ambientsound/pms/song"
+	"github.com/ambientsound/pms/songlist"
+	"github.com/stretchr/testify/assert"
+)
+
+// Test that isolating by tags which are not in the search index compares the
+// tag values directly, and does not need the search index.
+func TestLibraryIsolateUnindexed(t *testing.T) {
+	library := songlist.NewLibrary()
+	for _, file := range []string{"a.flac", "b.mp3", "c.flac", "d.ogg"} {
+		s := song.New()
+		s.SetTags(mpd.Attrs{
+			"file":   file,
+			"format": file[2:],
+		})
+		library.Add(s)
+	}
+
+	selection := library.Indices([]int{0, 3})
+	result, err := library.Isolate(selection, []string{"format"})
+	assert.Nil(t, err)
+	assert.Equal(t, 3, result.Len())
+	assert.Equal(t, "a.flac", result.Song(0).StringTags["file"])
+	assert.Equal(t, "c.flac", result.Song(1).StringTags["file"])
+	assert.Equal(t, "d.ogg", result.Song(2).StringTags["file"])
+
+	// Without a search index, indexed tags are also compared directly.
+	result, err = library.Isolate(selection, []string{"format", "artist"})
+	assert.Nil(t, err)
+	assert.Equal(t, 3, result.Len())
+}
+
+// Test that isolating by a tag with several values matches songs sharing any
+// one of the values.
+func TestLibraryIsolateMultiValue(t *testing.T) {
+	library := songlist.NewLibrary()
+	files := []string{"a", "b", "c", "d"}
+	for i, formats := range [][]string{{"flac", "mp3"}, {"mp3"}, {"ogg"}, {"ogg", "flac"}} {
+		s := song.New()
+		s.SetMultiTags(song.MultiTaglist{
+			"file":   {files[i]},
+			"format": formats,
+		}, nil)
+		library.Add(s)
+	}
+
+	selection := library.Indices([]int{1})
+	result, err := library.Isolate(selection, []string{"format"})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, result.Len())
+	assert.Equal(t, "a", result.Song(0).StringTags["file"])
+	assert.Equal(t, "b", result.Song(1).StringTags["file"])
+
+	// Songs sharing any value with the selection are nearby.
+	assert.Equal(t, 2, library.NextOf([]string{"format"}, 0, 1))
+	assert.Equal(t, 0, library.NextOf([]string{"format"}, 2, -1))
+}
+
+// Test that songs are found by fuzzy matching in the search index.
+func TestLibraryMatch(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pms-index")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	library := songlist.NewLibrary()
+	for _, tags := range []mpd.Attrs{
+		{"file": "a.flac", "artist": "Foo", "title": "Hello world", "time": "200"},
+		{"file": "b.flac", "artist": "Bar", "title": "Hello world", "time": "180"},
+		{"file": "c.flac", "artist": "Foo", "title": "Goodbye"},
+		{"file": "d.flac", "artist": "Foo", "title": "Hello world", "time": "300"},
+	} {
+		s := song.New()
+		s.SetTags(tags)
+		library.Add(s)
+	}
+
+	assert.Nil(t, library.OpenIndex(dir, 0))
+	defer library.CloseIndex()
+	library.ReIndex()
+	library.WaitReIndex()
+
+	s, err := library.Match(map[string]string{"artist": "foo", "title": "helo world"}, 201)
+	assert.Nil(t, err)
+	if assert.NotNil(t, s) {
+		assert.Equal(t, "a.flac", s.StringTags["file"])
+	}
+
+	// The duration is used to tell songs with the same tags apart.
+	s, err = library.Match(map[string]string{"artist": "foo", "title": "hello world"}, 299)
+	assert.Nil(t, err)
+	if assert.NotNil(t, s) {
+		assert.Equal(t, "d.flac", s.StringTags["file"])
+	}
+
+	// Songs without a length match any duration.
+	s, err = library.Match(map[string]string{"artist": "foo", "title": "goodbye"}, 100)
+	assert.Nil(t, err)
+	if assert.NotNil(t, s) {
+		assert.Equal(t, "c.flac", s.StringTags["file"])
+	}
+
+	// Every word must match.
+	s, err = library.Match(map[string]string{"artist": "foo", "title": "hello planet"}, -1)
+	assert.Nil(t, err)
+	assert.Nil(t, s)
+
+	// No song has this length.
+	s, err = library.Match(map[string]string{"artist": "bar", "title": "hello world"}, 240)
+	assert.Nil(t, err)
+	assert.Nil(t, s)
+
+	s, err = library.Match(map[string]string{"artist": "qux", "title": "something else"}, -1)
+	assert.Nil(t, err)
+	assert.Nil(t, s)
+
+	files := library.Files()
+	assert.Len(t, files, 4)
+	assert.Equal(t, "Bar", files["b.flac"].StringTags["artist"])
+}
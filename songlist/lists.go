@@ -0,0 +1,213 @@
+package songlist
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/ambientsound/gompd/mpd"
+	"github.com/ambientsound/pms/song"
+	"github.com/ambientsound/pms/utils"
+)
+
+// Opener is implemented by songlists whose entries refer to other songlists,
+// such as the lists view.
+type Opener interface {
+	// Open returns the songlist referred to by the entry at the given index.
+	Open(index int) (Songlist, error)
+}
+
+// Lists is a Songlist which shows the songlists in a collection instead of
+// songs. Each songlist is represented by a song with the tags 'name', 'type',
+// 'songs' and 'duration'. Removing and reordering entries removes and
+// reorders the songlists in the collection.
+type Lists struct {
+	BaseSonglist
+	collection *Collection
+	lists      []Songlist
+}
+
+// NewLists returns Lists, showing the songlists in the given collection.
+func NewLists(collection *Collection) (s *Lists) {
+	s = &Lists{
+		collection: collection,
+	}
+	s.Refresh()
+	return
+}
+
+// TypeName returns a short description of the kind of songlist.
+func TypeName(list Songlist) string {
+	switch list.(type) {
+	case *Queue:
+		return "queue"
+	case *Library:
+		return "library"
+	case *History:
+		return "history"
+	case *Search:
+		return "search"
+	case *Log:
+		return "messages"
+	case *Lists:
+		return "lists"
+	case *Stats:
+		return "statistics"
+	default:
+		return "playlist"
+	}
+}
+
+// Refresh updates the entries with the current songlists in the collection.
+// The lists view itself is not shown.
+func (s *Lists) Refresh() {
+	cursor := s.Cursor()
+	s.clear()
+	s.lists = make([]Songlist, 0, s.collection.Len())
+	for i := 0; i < s.collection.Len(); i++ {
+		list, _ := s.collection.Songlist(i)
+		if list == s {
+			continue
+		}
+		s.lists = append(s.lists, list)
+		s.add(listSong(list))
+	}
+	s.SetCursor(cursor)
+	s.SetUpdated()
+}
+
+// Songlist returns the songlist at the given index.
+func (s *Lists) Songlist(index int) Songlist {
+	if index < 0 || index >= len(s.lists) {
+		return nil
+	}
+	return s.lists[index]
+}
+
+// Open implements Opener.
+func (s *Lists) Open(index int) (Songlist, error) {
+	list := s.Songlist(index)
+	if list == nil {
+		return nil, fmt.Errorf("Out of bounds")
+	}
+	return list, nil
+}
+
+func (s *Lists) Name() string {
+	return "Lists"
+}
+
+// DefaultColumns implements Columnar.
+func (s *Lists) DefaultColumns() []string {
+	return []string{"name", "type", "songs", "duration"}
+}
+
+func (s *Lists) SetName(name string) error {
+	return fmt.Errorf("The list of songlists cannot be renamed.")
+}
+
+func (s *Lists) Clear() error {
+	return fmt.Errorf("The list of songlists cannot be cleared.")
+}
+
+func (s *Lists) Add(song *song.Song) error {
+	return fmt.Errorf("Songs cannot be added to the list of songlists.")
+}
+
+func (s *Lists) AddList(songlist Songlist) error {
+	return fmt.Errorf("Songs cannot be added to the list of songlists.")
+}
+
+func (s *Lists) Insert(song *song.Song, position int) error {
+	return fmt.Errorf("Songs cannot be added to the list of songlists.")
+}
+
+func (s *Lists) InsertList(songlist Songlist, position int) error {
+	return fmt.Errorf("Songs cannot be added to the list of songlists.")
+}
+
+func (s *Lists) Sort(fields []string) error {
+	return fmt.Errorf("The list of songlists cannot be sorted.")
+}
+
+// Remove implements Songlist.
+func (s *Lists) Remove(index int) error {
+	return s.RemoveIndices([]int{index})
+}
+
+// RemoveIndices removes the songlists at the given indices from the
+// collection. Songlists that cannot be removed, such as the queue, are kept,
+// and an error is returned.
+func (s *Lists) RemoveIndices(indices []int) error {
+	var err error
+
+	sort.Sort(sort.Reverse(sort.IntSlice(indices)))
+	for _, i := range indices {
+		list := s.Songlist(i)
+		if list == nil {
+			return fmt.Errorf("Out of bounds")
+		}
+		if deleteErr := list.Delete(); deleteErr != nil {
+			err = deleteErr
+			continue
+		}
+		for j := 0; j < s.collection.Len(); j++ {
+			if stored, _ := s.collection.Songlist(j); stored == list {
+				s.collection.Remove(j)
+				break
+			}
+		}
+	}
+
+	s.Refresh()
+
+	return err
+}
+
+// Reorder rearranges the songlists in the collection, so that the songlist at
+// index order[i] is placed at index i.
+func (s *Lists) Reorder(order []int) error {
+	if err := validateOrder(s.Len(), order); err != nil {
+		return err
+	}
+
+	// Translate the order of the visible entries into an order of the whole
+	// collection, where the lists view itself keeps its position.
+	positions := make([]int, 0, len(s.lists))
+	for i := 0; i < s.collection.Len(); i++ {
+		if list, _ := s.collection.Songlist(i); list != s {
+			positions = append(positions, i)
+		}
+	}
+	collectionOrder := identity(s.collection.Len())
+	for i, j := range order {
+		collectionOrder[positions[i]] = positions[j]
+	}
+	if err := s.collection.Reorder(collectionOrder); err != nil {
+		return err
+	}
+
+	lists := make([]Songlist, len(order))
+	for i, j := range order {
+		lists[i] = s.lists[j]
+	}
+	s.lists = lists
+
+	return s.BaseSonglist.Reorder(order)
+}
+
+// listSong creates a song that describes a songlist.
+func listSong(list Songlist) *song.Song {
+	duration := 0
+	for _, song := range list.Songs() {
+		duration += song.Time
+	}
+	s := song.New()
+	s.SetTags(mpd.Attrs{
+		"name":     list.Name(),
+		"type":     TypeName(list),
+		"songs":    strconv.Itoa(list.Len()),
+		"duration": utils.TimeString(duration),
+	})
+	return s
+}
@@ -0,0 +1,89 @@
+package songlist_test
+
+import (
+	"testing"
+
+	"github.com/ambientsound/gompd/mpd"
+	"github.com/ambientsound/pms/song"
+	"github.com/ambientsound/pms/songlist"
+	"github.com/stretchr/testify/assert"
+)
+
+func listNames(lists *songlist.Lists) []string {
+	names := make([]string, lists.Len())
+	for i := range names {
+		names[i] = lists.Song(i).StringTags["name"]
+	}
+	return names
+}
+
+func TestLists(t *testing.T) {
+	collection := songlist.NewCollection()
+	library := songlist.NewLibrary()
+	collection.Add(library)
+	for _, name := range []string{"foo", "bar", "baz"} {
+		list := songlist.New()
+		list.SetName(name)
+		collection.Add(list)
+	}
+	search := songlist.NewSearch()
+	search.SetName("qux")
+	s := song.New()
+	s.SetTags(mpd.Attrs{"file": "a.flac", "time": "65"})
+	search.Add(s)
+	collection.Add(search)
+
+	lists := songlist.NewLists(collection)
+	collection.Add(lists)
+	lists.Refresh()
+
+	assert.Equal(t, []string{"Library", "foo", "bar", "baz", "qux"}, listNames(lists))
+	assert.Equal(t, "library", lists.Song(0).StringTags["type"])
+	assert.Equal(t, "playlist", lists.Song(1).StringTags["type"])
+	assert.Equal(t, "search", lists.Song(4).StringTags["type"])
+	assert.Equal(t, "1", lists.Song(4).StringTags["songs"])
+	assert.Equal(t, "01:05", lists.Song(4).StringTags["duration"])
+
+	// Reordering entries reorders the collection, keeping the lists view in place.
+	assert.Nil(t, lists.Reorder([]int{0, 2, 3, 1, 4}))
+	assert.Equal(t, []string{"Library", "bar", "baz", "foo", "qux"}, listNames(lists))
+	for i, name := range []string{"Library", "bar", "baz", "foo", "qux", "Lists"} {
+		list, _ := collection.Songlist(i)
+		assert.Equal(t, name, list.Name())
+	}
+
+	// Lists that cannot be deleted are kept.
+	assert.NotNil(t, lists.RemoveIndices([]int{0, 3}))
+	assert.Equal(t, []string{"Library", "bar", "baz", "qux"}, listNames(lists))
+	assert.Equal(t, 5, collection.Len())
+}
+
+// Test that lists views are updated with replaced and changed songlists.
+func TestCollectionRefreshLists(t *testing.T) {
+	collection := songlist.NewCollection()
+	collection.Add(songlist.NewLibrary())
+	foo := songlist.New()
+	foo.SetName("foo")
+	collection.Add(foo)
+
+	lists := songlist.NewLists(collection)
+	collection.Add(lists)
+	lists.Refresh()
+	assert.Equal(t, "0", lists.Song(1).StringTags["songs"])
+
+	s := song.New()
+	s.SetTags(mpd.Attrs{"file": "a.flac", "time": "65"})
+	foo.Add(s)
+	library := songlist.NewLibrary()
+	library.Add(s)
+	collection.Replace(library)
+	bar := songlist.New()
+	bar.SetName("bar")
+	collection.Add(bar)
+
+	collection.RefreshLists()
+	assert.Equal(t, []string{"Library", "foo", "bar"}, listNames(lists))
+	assert.True(t, lists.Songlist(0) == songlist.Songlist(library))
+	assert.Equal(t, "1", lists.Song(0).StringTags["songs"])
+	assert.Equal(t, "1", lists.Song(1).StringTags["songs"])
+}
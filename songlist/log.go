@@ -0,0 +1,106 @@
+package songlist
+
+import (
+	"fmt"
+
+	"github.com/ambientsound/gompd/mpd"
+	"github.com/ambientsound/pms/message"
+	"github.com/ambientsound/pms/song"
+)
+
+// LogTimeFormat is the format of the 'timestamp' tag in message log entries.
+const LogTimeFormat = "2006-01-02 15:04:05"
+
+// Log is a read-only Songlist which shows messages instead of songs. Each
+// message is represented by a song with the tags 'timestamp', 'severity' and
+// 'message'.
+type Log struct {
+	BaseSonglist
+	buffer     *message.Buffer
+	severities map[int]bool
+}
+
+// NewLog returns Log, showing the messages in the given buffer. If any
+// severities are given, only messages with those severities are shown.
+func NewLog(buffer *message.Buffer, severities map[int]bool) (s *Log) {
+	s = &Log{
+		buffer:     buffer,
+		severities: severities,
+	}
+	s.Refresh()
+	return
+}
+
+// Refresh updates the entries with the current messages in the buffer. If the
+// cursor is at the most recent message, it stays there.
+func (s *Log) Refresh() {
+	cursor := s.Cursor()
+	last := cursor >= s.Len()-1
+	s.clear()
+	for _, msg := range s.buffer.Messages() {
+		if len(s.severities) == 0 || s.severities[msg.Severity] {
+			s.add(logSong(msg))
+		}
+	}
+	if last {
+		cursor = s.Len() - 1
+	}
+	s.SetCursor(cursor)
+	s.SetUpdated()
+}
+
+func (s *Log) Name() string {
+	return "Messages"
+}
+
+// DefaultColumns implements Columnar.
+func (s *Log) DefaultColumns() []string {
+	return []string{"timestamp", "severity", "message"}
+}
+
+func (s *Log) SetName(name string) error {
+	return fmt.Errorf("The message log name cannot be changed.")
+}
+
+func (s *Log) Clear() error {
+	return fmt.Errorf("The message log is read-only.")
+}
+
+func (s *Log) Add(song *song.Song) error {
+	return fmt.Errorf("The message log is read-only.")
+}
+
+func (s *Log) AddList(songlist Songlist) error {
+	return fmt.Errorf("The message log is read-only.")
+}
+
+func (s *Log) Insert(song *song.Song, position int) error {
+	return fmt.Errorf("The message log is read-only.")
+}
+
+func (s *Log) InsertList(songlist Songlist, position int) error {
+	return fmt.Errorf("The message log is read-only.")
+}
+
+func (s *Log) Remove(index int) error {
+	return fmt.Errorf("The message log is read-only.")
+}
+
+func (s *Log) RemoveIndices(indices []int) error {
+	return fmt.Errorf("The message log is read-only.")
+}
+
+func (s *Log) Reorder(order []int) error {
+	return fmt.Errorf("The message log is read-only.")
+}
+
+// logSong creates a song from a message.
+func logSong(msg message.Message) *song.Song {
+	s := song.New()
+	s.SetTags(mpd.Attrs{
+		"timestamp": msg.Time.Format(LogTimeFormat),
+		"severity":  msg.SeverityName(),
+		"message":   msg.Text,
+	})
+	return s
+}
@@ -0,0 +1,170 @@
+package songlist
+
+import (
+	"fmt"
+
+	"github.com/ambientsound/pms/song"
+)
+
+// MoveOrder returns a new song order for a list of the given length, where the
+// songs at the given indices are moved by offset positions. Songs that are
+// moved keep their relative order. The offset is limited so that no song is
+// moved beyond the start or end of the list.
+func MoveOrder(length int, indices []int, offset int) ([]int, error) {
+	selected, err := selectionMask(length, indices)
+	if err != nil {
+		return nil, err
+	}
+
+	// Limit the offset to the list boundaries.
+	first, last := firstLast(selected)
+	if first+offset < 0 {
+		offset = -first
+	} else if last+offset >= length {
+		offset = length - 1 - last
+	}
+	if offset == 0 {
+		return nil, fmt.Errorf("Cannot move songs any further")
+	}
+
+	order := identity(length)
+
+	// Move songs one step at a time, swapping each selected song with
+	// its unselected neighbour.
+	for ; offset < 0; offset++ {
+		for i := 1; i < length; i++ {
+			if selected[i] && !selected[i-1] {
+				order[i-1], order[i] = order[i], order[i-1]
+				selected[i-1], selected[i] = true, false
+			}
+		}
+	}
+	for ; offset > 0; offset-- {
+		for i := length - 2; i >= 0; i-- {
+			if selected[i] && !selected[i+1] {
+				order[i+1], order[i] = order[i], order[i+1]
+				selected[i+1], selected[i] = true, false
+			}
+		}
+	}
+
+	return order, nil
+}
+
+// MoveToOrder returns a new song order for a list of the given length, where
+// the songs at the given indices are moved, in order, to a contiguous block
+// starting at position. The position is limited so that the block fits within
+// the list.
+func MoveToOrder(length int, indices []int, position int) ([]int, error) {
+	selected, err := selectionMask(length, indices)
+	if err != nil {
+		return nil, err
+	}
+
+	moved := make([]int, 0, length)
+	rest := make([]int, 0, length)
+	for i := range selected {
+		if selected[i] {
+			moved = append(moved, i)
+		} else {
+			rest = append(rest, i)
+		}
+	}
+
+	if position < 0 {
+		position = 0
+	} else if position > len(rest) {
+		position = len(rest)
+	}
+
+	order := make([]int, 0, length)
+	order = append(order, rest[:position]...)
+	order = append(order, moved...)
+	order = append(order, rest[position:]...)
+
+	return order, nil
+}
+
+// Reorder rearranges the songlist, so that the song at index order[i] is placed
+// at index i. The cursor and selection follow the songs they point to.
+func (s *BaseSonglist) Reorder(order []int) error {
+	if err := validateOrder(s.Len(), order); err != nil {
+		return err
+	}
+
+	s.Lock()
+	songs := make([]*song.Song, len(order))
+	selection := make(map[int]struct{}, len(s.selection))
+	cursor := s.cursor
+	for i, j := range order {
+		songs[i] = s.songs[j]
+		if _, ok := s.selection[j]; ok {
+			selection[i] = struct{}{}
+		}
+		if j == s.cursor {
+			cursor = i
+		}
+	}
+	s.songs = songs
+	s.selection = selection
+	s.Unlock()
+
+	s.SetCursor(cursor)
+
+	return nil
+}
+
+// validateOrder returns an error if order is not a permutation of the indices
+// in a list of the given length.
+func validateOrder(length int, order []int) error {
+	if len(order) != length {
+		return fmt.Errorf("Song order has %d entries, expected %d", len(order), length)
+	}
+	seen := make([]bool, length)
+	for _, i := range order {
+		if i < 0 || i >= length || seen[i] {
+			return fmt.Errorf("Song order is not a permutation of the list")
+		}
+		seen[i] = true
+	}
+	return nil
+}
+
+// selectionMask converts a slice of indices into a boolean slice, where the
+// selected indices are true.
+func selectionMask(length int, indices []int) ([]bool, error) {
+	if len(indices) == 0 {
+		return nil, fmt.Errorf("No songs to move")
+	}
+	selected := make([]bool, length)
+	for _, i := range indices {
+		if i < 0 || i >= length {
+			return nil, fmt.Errorf("Out of bounds")
+		}
+		selected[i] = true
+	}
+	return selected, nil
+}
+
+// firstLast returns the first and last true indices in a boolean slice.
+func firstLast(selected []bool) (int, int) {
+	first, last := -1, -1
+	for i := range selected {
+		if selected[i] {
+			if first == -1 {
+				first = i
+			}
+			last = i
+		}
+	}
+	return first, last
+}
+
+// identity returns the order of an unchanged list of the given length.
+func identity(length int) []int {
+	order := make([]int, length)
+	for i := range order {
+		order[i] = i
+	}
+	return order
+}
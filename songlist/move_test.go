@@ -0,0 +1,90 @@
+package songlist_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/ambientsound/gompd/mpd"
+	"github.com/ambientsound/pms/song"
+	"github.com/ambientsound/pms/songlist"
+	"github.com/stretchr/testify/assert"
+)
+
+var moveOrderTests = []struct {
+	length  int
+	indices []int
+	offset  int
+	order   []int
+	success bool
+}{
+	{5, []int{2}, -1, []int{0, 2, 1, 3, 4}, true},
+	{5, []int{2}, 1, []int{0, 1, 3, 2, 4}, true},
+	{5, []int{1, 2}, -1, []int{1, 2, 0, 3, 4}, true},
+	{5, []int{1, 3}, -1, []int{1, 0, 3, 2, 4}, true},
+	{5, []int{1, 3}, 1, []int{0, 2, 1, 4, 3}, true},
+	{5, []int{3}, -10, []int{3, 0, 1, 2, 4}, true},
+	{5, []int{1, 3}, 10, []int{0, 2, 1, 4, 3}, true},
+	{5, []int{0}, -1, nil, false},
+	{5, []int{4}, 1, nil, false},
+	{5, []int{5}, 1, nil, false},
+	{5, []int{}, 1, nil, false},
+}
+
+func TestMoveOrder(t *testing.T) {
+	for n, test := range moveOrderTests {
+		order, err := songlist.MoveOrder(test.length, test.indices, test.offset)
+		if test.success {
+			assert.Nil(t, err, "test %d", n+1)
+		} else {
+			assert.NotNil(t, err, "test %d", n+1)
+		}
+		assert.Equal(t, test.order, order, "test %d", n+1)
+	}
+}
+
+var moveToOrderTests = []struct {
+	length   int
+	indices  []int
+	position int
+	order    []int
+}{
+	{5, []int{3}, 0, []int{3, 0, 1, 2, 4}},
+	{5, []int{1, 3}, 0, []int{1, 3, 0, 2, 4}},
+	{5, []int{1, 3}, 2, []int{0, 2, 1, 3, 4}},
+	{5, []int{0, 1}, 10, []int{2, 3, 4, 0, 1}},
+	{5, []int{4}, -1, []int{4, 0, 1, 2, 3}},
+}
+
+func TestMoveToOrder(t *testing.T) {
+	for n, test := range moveToOrderTests {
+		order, err := songlist.MoveToOrder(test.length, test.indices, test.position)
+		assert.Nil(t, err, "test %d", n+1)
+		assert.Equal(t, test.order, order, "test %d", n+1)
+	}
+}
+
+func TestReorder(t *testing.T) {
+	list := songlist.New()
+	for i := 0; i < 4; i++ {
+		s := song.New()
+		s.SetTags(mpd.Attrs{"title": fmt.Sprintf("%d", i)})
+		list.Add(s)
+	}
+	list.SetCursor(1)
+	list.SetSelected(3, true)
+
+	err := list.Reorder([]int{3, 2, 1, 0})
+	assert.Nil(t, err)
+
+	titles := make([]string, 0, list.Len())
+	for _, s := range list.Songs() {
+		titles = append(titles, s.StringTags["title"])
+	}
+	assert.Equal(t, []string{"3", "2", "1", "0"}, titles)
+	assert.Equal(t, 2, list.Cursor())
+	assert.True(t, list.Selected(0))
+	assert.False(t, list.Selected(3))
+
+	assert.NotNil(t, list.Reorder([]int{0, 1, 2}))
+	assert.NotNil(t, list.Reorder([]int{0, 0, 1, 2}))
+}
@@ -2,9 +2,12 @@ package songlist
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/ambientsound/gompd/mpd"
 	"github.com/ambientsound/pms/console"
+	pms_mpd "github.com/ambientsound/pms/mpd"
 	"github.com/ambientsound/pms/song"
 )
 
@@ -12,11 +15,13 @@ import (
 type Queue struct {
 	BaseSonglist
 	mpdClient func() *mpd.Client
+	rawClient func() (*pms_mpd.RawClient, error)
 }
 
-func NewQueue(mpdClient func() *mpd.Client) (s *Queue) {
+func NewQueue(mpdClient func() *mpd.Client, rawClient func() (*pms_mpd.RawClient, error)) (s *Queue) {
 	s = &Queue{}
 	s.mpdClient = mpdClient
+	s.rawClient = rawClient
 	s.clear()
 	return
 }
@@ -51,6 +56,31 @@ func (s *Queue) AddList(songlist Songlist) error {
 	return commandList.End()
 }
 
+// AddListID appends a songlist to the queue, and returns the ID of the first
+// song that was added. Unlike AddList, directories cannot be added.
+func (s *Queue) AddListID(songlist Songlist) (int, error) {
+	client := s.mpdClient()
+	if client == nil {
+		return song.NullID, fmt.Errorf("Cannot communicate with MPD")
+	}
+	commandList := client.BeginCommandList()
+	if commandList == nil {
+		return song.NullID, fmt.Errorf("Cannot begin command list")
+	}
+	songs := songlist.Songs()
+	if len(songs) == 0 {
+		return song.NullID, fmt.Errorf("No songs to add")
+	}
+	first := commandList.AddID(songs[0].StringTags["file"], -1)
+	for _, song := range songs[1:] {
+		commandList.AddID(song.StringTags["file"], -1)
+	}
+	if err := commandList.End(); err != nil {
+		return song.NullID, err
+	}
+	return first.Value()
+}
+
 // Insert inserts a song at a specified position in the queue.
 func (s *Queue) Insert(song *song.Song, position int) error {
 	client := s.mpdClient()
@@ -84,6 +114,77 @@ func (s *Queue) InsertList(list Songlist, position int) error {
 	return commandList.End()
 }
 
+// InsertListNext inserts the songs in a songlist directly after the currently
+// playing song. MPD 0.23 and newer is told to insert relative to the current
+// song, so that the songs end up in the right place even if the queue has
+// changed in the meantime. Older servers get the absolute position instead.
+func (s *Queue) InsertListNext(list Songlist, position int) error {
+	if s.rawClient == nil {
+		return s.InsertList(list, position)
+	}
+
+	client, err := s.rawClient()
+	if err != nil {
+		return err
+	}
+
+	if !client.Version().AtLeast(0, 23) {
+		console.Log("MPD %s does not support relative queue positions, inserting at position %d", client.Version(), position)
+		return s.InsertList(list, position)
+	}
+
+	songs := list.Songs()
+	commands := make([]string, len(songs))
+	for i, song := range songs {
+		commands[i] = fmt.Sprintf("addid %s +%d", pms_mpd.Quote(song.StringTags["file"]), i)
+	}
+
+	return client.CommandList(commands)
+}
+
+// ShuffleRange tells MPD to shuffle the songs from position start up to, but
+// not including, position end. If start or end is negative, the entire queue
+// is shuffled.
+func (s *Queue) ShuffleRange(start, end int) error {
+	client := s.mpdClient()
+	if client == nil {
+		return fmt.Errorf("Cannot communicate with MPD")
+	}
+	return client.Shuffle(start, end)
+}
+
+// SetPriority sets the priority of the songs at the given indices. In random
+// mode, MPD plays songs with higher priority first. Priorities range from 0
+// to 255, where 0 is the default.
+func (s *Queue) SetPriority(indices []int, prio int) error {
+	if prio < 0 || prio > 255 {
+		return fmt.Errorf("Priority must be between 0 and 255")
+	}
+	if s.rawClient == nil {
+		return fmt.Errorf("Cannot communicate with MPD")
+	}
+
+	ids := make([]string, 0, len(indices))
+	for _, i := range indices {
+		if song := s.Song(i); song != nil {
+			ids = append(ids, strconv.Itoa(song.ID))
+		}
+	}
+	if len(ids) == 0 {
+		return fmt.Errorf("No songs to prioritize")
+	}
+
+	client, err := s.rawClient()
+	if err != nil {
+		return err
+	}
+	if !client.Version().AtLeast(0, 17) {
+		return fmt.Errorf("MPD %s does not support song priorities", client.Version())
+	}
+
+	return client.Command(fmt.Sprintf("prioid %d %s", prio, strings.Join(ids, " ")))
+}
+
 func (s *Queue) SetName(name string) error {
 	return fmt.Errorf("The queue name cannot be changed.")
 }
@@ -135,9 +236,45 @@ func (s *Queue) RemoveIndices(indices []int) error {
 	return commandList.End()
 }
 
+// Reorder rearranges MPD's queue, so that the song at index order[i] is placed
+// at index i. Songs are moved using their IDs, so that IDs and priorities are
+// kept intact. The local copy of the queue is updated when MPD reports the
+// changes.
+func (s *Queue) Reorder(order []int) error {
+	if err := validateOrder(s.Len(), order); err != nil {
+		return err
+	}
+
+	client := s.mpdClient()
+	if client == nil {
+		return fmt.Errorf("Cannot communicate with MPD")
+	}
+	commandList := client.BeginCommandList()
+	if commandList == nil {
+		return fmt.Errorf("Cannot begin command list")
+	}
+
+	// Keep track of the queue order as MPD will see it after each move.
+	current := identity(s.Len())
+	for i, j := range order {
+		if current[i] == j {
+			continue
+		}
+		pos := i
+		for current[pos] != j {
+			pos++
+		}
+		commandList.MoveID(s.Song(j).ID, i)
+		copy(current[i+1:pos+1], current[i:pos])
+		current[i] = j
+	}
+
+	return commandList.End()
+}
+
 // Merge incorporates songs from another songlist, replacing songs that has the same position.
 func (q *Queue) Merge(s Songlist) (*Queue, error) {
-	newQueue := NewQueue(q.mpdClient)
+	newQueue := NewQueue(q.mpdClient, q.rawClient)
 
 	oldSongs := q.Songs()
 	for i := range oldSongs {
@@ -0,0 +1,206 @@
+package songlist
+
+import (
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ambientsound/pms/song"
+)
+
+// discDirectory matches directory names used for the individual discs of an
+// album, such as "CD1" or "Disc 2".
+var discDirectory = regexp.MustCompile(`(?i)^(cd|disc|disk)\s*\d+$`)
+
+// albumKey returns a key that identifies the album of a song, based on the
+// album tag and the directory of the song file. Separate directories for each
+// disc of an album are considered to be the same album. Songs without an
+// album tag return an empty key.
+func albumKey(s *song.Song) string {
+	album := s.StringTags["album"]
+	if len(album) == 0 {
+		return ""
+	}
+	dir := path.Dir(s.StringTags["file"])
+	if discDirectory.MatchString(path.Base(dir)) {
+		dir = path.Dir(dir)
+	}
+	return dir + "\x00" + album
+}
+
+// parseNumber parses tags such as track and disc, which can be given either
+// as a single number or as "number/total". If the total is not given, it is
+// returned as zero.
+func parseNumber(value string) (number, total int, ok bool) {
+	parts := strings.SplitN(value, "/", 2)
+	number, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+	if err != nil {
+		return 0, 0, false
+	}
+	if len(parts) > 1 {
+		total, _ = strconv.Atoi(strings.TrimSpace(parts[1]))
+	}
+	return number, total, true
+}
+
+// ReportTags checks the tags of songs for common problems, and returns a
+// songlist for each kind of problem found. Songs missing any of the required
+// tags are reported, and so are albums with inconsistent album artists or
+// dates, gaps in the track numbers, or mismatched disc numbers. Songs in the
+// album reports are grouped by album. Problems that are not found do not get
+// a songlist.
+func ReportTags(songs []*song.Song, required []string) []Songlist {
+	reports := make([]Songlist, 0)
+	add := func(name string, songs []*song.Song) {
+		if len(songs) == 0 {
+			return
+		}
+		list := NewSearch()
+		list.SetName(name)
+		for _, s := range songs {
+			list.Add(s)
+		}
+		reports = append(reports, list)
+	}
+
+	missing := make([]*song.Song, 0)
+	for _, s := range songs {
+		for _, tag := range required {
+			if len(s.StringTags[tag]) == 0 {
+				missing = append(missing, s)
+				break
+			}
+		}
+	}
+	add("Missing tags: "+strings.Join(required, ", "), missing)
+
+	albums := make(map[string][]*song.Song)
+	keys := make([]string, 0)
+	for _, s := range songs {
+		key := albumKey(s)
+		if len(key) == 0 {
+			continue
+		}
+		if _, ok := albums[key]; !ok {
+			keys = append(keys, key)
+		}
+		albums[key] = append(albums[key], s)
+	}
+	sort.Strings(keys)
+
+	checks := []struct {
+		name  string
+		check func([]*song.Song) bool
+	}{
+		{"Inconsistent albumartist", func(album []*song.Song) bool { return inconsistent(album, "albumartist") }},
+		{"Inconsistent date", func(album []*song.Song) bool { return inconsistent(album, "date") }},
+		{"Track number gaps", trackGaps},
+		{"Mismatched disc counts", discMismatch},
+	}
+
+	for _, check := range checks {
+		found := make([]*song.Song, 0)
+		for _, key := range keys {
+			if check.check(albums[key]) {
+				found = append(found, albums[key]...)
+			}
+		}
+		add(check.name, found)
+	}
+
+	return reports
+}
+
+// inconsistent returns true if the songs do not all have the same value for a tag.
+func inconsistent(songs []*song.Song, tag string) bool {
+	for _, s := range songs[1:] {
+		if s.StringTags[tag] != songs[0].StringTags[tag] {
+			return true
+		}
+	}
+	return false
+}
+
+// trackGaps returns true if any disc of an album is missing track numbers.
+// Tracks are expected to be numbered from one, up to the total number of
+// tracks if it is given. Songs without a track number are ignored.
+func trackGaps(songs []*song.Song) bool {
+	type disc struct {
+		tracks map[int]bool
+		max    int
+	}
+	discs := make(map[int]*disc)
+
+	for _, s := range songs {
+		number, total, ok := parseNumber(s.StringTags["track"])
+		if !ok {
+			continue
+		}
+		discNumber, _, _ := parseNumber(s.StringTags["disc"])
+		d, ok := discs[discNumber]
+		if !ok {
+			d = &disc{tracks: make(map[int]bool)}
+			discs[discNumber] = d
+		}
+		d.tracks[number] = true
+		if number > d.max {
+			d.max = number
+		}
+		if total > d.max {
+			d.max = total
+		}
+	}
+
+	for _, d := range discs {
+		for i := 1; i <= d.max; i++ {
+			if !d.tracks[i] {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// discMismatch returns true if only some of the songs of an album have a disc
+// number, if the songs disagree on the total number of discs, or if the disc
+// numbers do not match the total number of discs.
+func discMismatch(songs []*song.Song) bool {
+	discs := make(map[int]bool)
+	total := -1
+	tagged := 0
+
+	for _, s := range songs {
+		number, discTotal, ok := parseNumber(s.StringTags["disc"])
+		if !ok {
+			continue
+		}
+		tagged++
+		discs[number] = true
+		if total >= 0 && discTotal != total {
+			return true
+		}
+		total = discTotal
+	}
+
+	if tagged == 0 {
+		return false
+	}
+	if tagged != len(songs) {
+		return true
+	}
+	if total > 0 {
+		if len(discs) != total {
+			return true
+		}
+		for number := range discs {
+			if number < 1 || number > total {
+				return true
+			}
+		}
+	}
+
+	return false
+}
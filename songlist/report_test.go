@@ -0,0 +1,68 @@
+package songlist_test
+
+import (
+	"testing"
+
+	"github.com/ambientsound/gompd/mpd"
+	"github.com/ambientsound/pms/song"
+	"github.com/ambientsound/pms/songlist"
+	"github.com/stretchr/testify/assert"
+)
+
+func reportSongs() []*song.Song {
+	songs := make([]*song.Song, 0)
+	for _, tags := range []mpd.Attrs{
+		// A consistent album.
+		{"file": "a/1.flac", "artist": "A", "title": "1", "album": "A", "albumartist": "A", "date": "2000", "track": "1/2"},
+		{"file": "a/2.flac", "artist": "A", "title": "2", "album": "A", "albumartist": "A", "date": "2000", "track": "2/2"},
+
+		// Inconsistent album artist and date, and missing title.
+		{"file": "b/1.flac", "artist": "B", "title": "1", "album": "B", "albumartist": "B", "date": "2001", "track": "1"},
+		{"file": "b/2.flac", "artist": "B", "album": "B", "date": "2002", "track": "2"},
+
+		// Missing tracks, and disc numbers spread over directories.
+		{"file": "c/CD1/1.flac", "artist": "C", "title": "1", "album": "C", "track": "1", "disc": "1/2"},
+		{"file": "c/CD1/3.flac", "artist": "C", "title": "3", "album": "C", "track": "3", "disc": "1/2"},
+		{"file": "c/CD2/1.flac", "artist": "C", "title": "1", "album": "C", "track": "1", "disc": "2/2"},
+
+		// Missing the last track, and a disc number outside the total.
+		{"file": "d/1.flac", "artist": "D", "title": "1", "album": "D", "track": "1/3", "disc": "2/1"},
+		{"file": "d/2.flac", "artist": "D", "title": "2", "album": "D", "track": "2/3", "disc": "2/1"},
+
+		// No album.
+		{"file": "e.flac", "artist": "E", "title": "E"},
+	} {
+		s := song.New()
+		s.SetTags(tags)
+		songs = append(songs, s)
+	}
+	return songs
+}
+
+func TestReportTags(t *testing.T) {
+	reports := songlist.ReportTags(reportSongs(), []string{"artist", "title"})
+
+	expected := []struct {
+		name  string
+		files []string
+	}{
+		{"Missing tags: artist, title", []string{"b/2.flac"}},
+		{"Inconsistent albumartist", []string{"b/1.flac", "b/2.flac"}},
+		{"Inconsistent date", []string{"b/1.flac", "b/2.flac"}},
+		{"Track number gaps", []string{"c/CD1/1.flac", "c/CD1/3.flac", "c/CD2/1.flac", "d/1.flac", "d/2.flac"}},
+		{"Mismatched disc counts", []string{"d/1.flac", "d/2.flac"}},
+	}
+
+	if !assert.Len(t, reports, len(expected)) {
+		return
+	}
+	for i := range expected {
+		assert.Equal(t, expected[i].name, reports[i].Name())
+		assert.Equal(t, expected[i].files, files(reports[i]))
+	}
+}
+
+func TestReportTagsNoProblems(t *testing.T) {
+	reports := songlist.ReportTags(reportSongs()[:2], []string{"artist"})
+	assert.Len(t, reports, 0)
+}
@@ -0,0 +1,13 @@
+package songlist
+
+// Search is a Songlist holding the results of a search in the song library.
+type Search struct {
+	BaseSonglist
+}
+
+// NewSearch returns Search.
+func NewSearch() (s *Search) {
+	s = &Search{}
+	s.clear()
+	return
+}
@@ -0,0 +1,85 @@
+package songlist
+
+import (
+	"math/rand"
+
+	"github.com/ambientsound/pms/song"
+)
+
+// ShuffleOrder returns a new song order for a list of the given length, where
+// the songs at the given indices are shuffled among those positions. Songs at
+// other positions stay in place.
+func ShuffleOrder(length int, indices []int, r *rand.Rand) ([]int, error) {
+	selected, err := selectionMask(length, indices)
+	if err != nil {
+		return nil, err
+	}
+
+	positions := maskPositions(selected)
+	shuffled := make([]int, len(positions))
+	copy(shuffled, positions)
+	r.Shuffle(len(shuffled), func(i, j int) {
+		shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+	})
+
+	order := identity(length)
+	for i, pos := range positions {
+		order[pos] = shuffled[i]
+	}
+
+	return order, nil
+}
+
+// ShuffleGroupsOrder returns a new song order where the songs at the given
+// indices are grouped by the values of the given tags, and the groups are
+// shuffled. Songs within a group keep their relative order. The shuffled songs
+// are placed in the positions of the original songs; songs at other positions
+// stay in place.
+func ShuffleGroupsOrder(songs []*song.Song, indices []int, tags []string, r *rand.Rand) ([]int, error) {
+	selected, err := selectionMask(len(songs), indices)
+	if err != nil {
+		return nil, err
+	}
+
+	positions := maskPositions(selected)
+
+	// Group songs by tag values, in order of first appearance.
+	groups := make([][]int, 0)
+	keys := make(map[string]int)
+	for _, pos := range positions {
+		key := GroupKey(songs[pos], tags)
+		g, ok := keys[key]
+		if !ok {
+			g = len(groups)
+			keys[key] = g
+			groups = append(groups, make([]int, 0))
+		}
+		groups[g] = append(groups[g], pos)
+	}
+
+	r.Shuffle(len(groups), func(i, j int) {
+		groups[i], groups[j] = groups[j], groups[i]
+	})
+
+	order := identity(len(songs))
+	i := 0
+	for _, group := range groups {
+		for _, pos := range group {
+			order[positions[i]] = pos
+			i++
+		}
+	}
+
+	return order, nil
+}
+
+// maskPositions returns the true indices in a boolean slice.
+func maskPositions(selected []bool) []int {
+	positions := make([]int, 0, len(selected))
+	for i := range selected {
+		if selected[i] {
+			positions = append(positions, i)
+		}
+	}
+	return positions
+}
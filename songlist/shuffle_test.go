@@ -0,0 +1,67 @@
+package songlist_test
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/ambientsound/gompd/mpd"
+	"github.com/ambientsound/pms/song"
+	"github.com/ambientsound/pms/songlist"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShuffleOrder(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	indices := []int{1, 2, 4, 6}
+
+	for n := 0; n < 20; n++ {
+		order, err := songlist.ShuffleOrder(8, indices, r)
+		assert.Nil(t, err)
+
+		// Unselected songs must stay in place.
+		for _, i := range []int{0, 3, 5, 7} {
+			assert.Equal(t, i, order[i])
+		}
+
+		// Selected songs must be shuffled among the selected positions.
+		shuffled := []int{order[1], order[2], order[4], order[6]}
+		sort.Ints(shuffled)
+		assert.Equal(t, indices, shuffled)
+	}
+
+	_, err := songlist.ShuffleOrder(8, []int{}, r)
+	assert.NotNil(t, err)
+}
+
+func TestShuffleGroupsOrder(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	albums := []string{"a", "a", "a", "b", "b", "c", "c", "c", "c"}
+	songs := make([]*song.Song, len(albums))
+	indices := make([]int, len(albums))
+	for i, album := range albums {
+		songs[i] = song.New()
+		songs[i].SetTags(mpd.Attrs{"album": album})
+		indices[i] = i
+	}
+
+	for n := 0; n < 20; n++ {
+		order, err := songlist.ShuffleGroupsOrder(songs, indices, []string{"album"}, r)
+		assert.Nil(t, err)
+
+		// Each album must be contiguous, with tracks in their original order.
+		first := map[string]int{"a": 0, "b": 3, "c": 5}
+		for i := range order {
+			if i > 0 && albums[order[i]] == albums[order[i-1]] {
+				assert.Equal(t, order[i-1]+1, order[i])
+			} else {
+				assert.Equal(t, first[albums[order[i]]], order[i])
+			}
+		}
+
+		sorted := make([]int, len(order))
+		copy(sorted, order)
+		sort.Ints(sorted)
+		assert.Equal(t, indices, sorted)
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/ambientsound/gompd/mpd"
 
 	"github.com/ambientsound/pms/console"
+	pms_mpd "github.com/ambientsound/pms/mpd"
 	"github.com/ambientsound/pms/song"
 )
 
@@ -29,6 +30,7 @@ type Songlist interface {
 	NextOf([]string, int, int) int
 	Remove(int) error
 	RemoveIndices([]int) error
+	Reorder([]int) error
 	Replace(int, *song.Song) error
 	SetName(string) error
 	Song(int) *song.Song
@@ -36,6 +38,7 @@ type Songlist interface {
 	Sort([]string) error
 	Truncate(int) error
 	Unlock()
+	UpdateColumns()
 
 	ClearSelection()
 	Columns([]string) Columns
@@ -306,6 +309,7 @@ func (s *BaseSonglist) Songs() []*song.Song {
 }
 
 // NextOf searches forwards or backwards for songs having different tags than the specified song.
+// Tags with several values are considered equal if they have at least one value in common.
 // The index of the next song is returned.
 func (s *BaseSonglist) NextOf(tags []string, index int, direction int) int {
 	offset := func(i int) int {
@@ -330,7 +334,7 @@ func (s *BaseSonglist) NextOf(tags []string, index int, direction int) int {
 			break
 		}
 		for _, tag := range tags {
-			if check.StringTags[tag] != song.StringTags[tag] {
+			if !check.SharesValue(song, tag) {
 				//console.Log("NextOf: tag '%s' on source '%s' differs from destination '%s', breaking", tag, check.StringTags[tag], song.StringTags[tag])
 				return index + offset(index)
 			}
@@ -341,38 +345,27 @@ func (s *BaseSonglist) NextOf(tags []string, index int, direction int) int {
 	return index + offset(index)
 }
 
-// Sort sorts the songlist by the given tags. The first tag is sorted normally,
-// while the remaining tags are used for stable sorting.
+// Sort sorts the songlist by the given tags. The most significant tag is
+// given last. Tags prefixed with a minus sign are sorted in descending order.
+// The sort is stable, so songs that compare equal keep their relative order.
 func (s *BaseSonglist) Sort(fields []string) error {
-	if len(fields) == 0 {
-		return fmt.Errorf("Cannot sort without sort criteria")
+	keys, err := ParseSortKeys(fields)
+	if err != nil {
+		return err
 	}
+
 	s.Lock()
 	defer s.Unlock()
 
-	stable := false
-	for _, field := range fields {
-		s.sortBy(field, stable)
-		stable = true
-	}
+	timer := time.Now()
+	sort.SliceStable(s.songs, func(a, b int) bool {
+		return CompareSongs(s.songs[a], s.songs[b], keys) < 0
+	})
+	console.Log("Sorted '%s' by %v in %s", s.Name(), fields, time.Since(timer).String())
 
 	return nil
 }
 
-// sortBy sorts the songlist by the given tag, optionally using stable sort.
-func (s *BaseSonglist) sortBy(field string, stable bool) {
-	sortFunc := func(a, b int) bool {
-		return s.songs[a].SortTags[field] < s.songs[b].SortTags[field]
-	}
-	timer := time.Now()
-	if stable {
-		sort.SliceStable(s.songs, sortFunc)
-	} else {
-		sort.Slice(s.songs, sortFunc)
-	}
-	console.Log("Sorted '%s' by '%s' in %s", s.Name(), field, time.Since(timer).String())
-}
-
 func (s *BaseSonglist) Len() int {
 	return len(s.songs)
 }
@@ -390,6 +383,16 @@ func (s *BaseSonglist) AddFromAttrlist(attrlist []mpd.Attrs) {
 	}
 }
 
+// AddFromMultiAttrlist adds songs where each tag may have several values,
+// calculating their tags using tagging.
+func (s *BaseSonglist) AddFromMultiAttrlist(attrlist []pms_mpd.MultiAttrs, tagging *song.Tagging) {
+	for _, attrs := range attrlist {
+		newSong := song.New()
+		newSong.SetMultiTags(song.MultiTaglist(attrs), tagging)
+		s.add(newSong)
+	}
+}
+
 // Updated returns the timestamp of when this songlist was last updated.
 func (s *BaseSonglist) Updated() time.Time {
 	return s.updated
@@ -0,0 +1,94 @@
+package songlist
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ambientsound/pms/song"
+	"github.com/ambientsound/pms/utils"
+)
+
+// naturalSortTags are tags that contain numbers, and are sorted so that "2"
+// comes before "10".
+var naturalSortTags = map[string]bool{
+	"date":         true,
+	"disc":         true,
+	"originaldate": true,
+	"originalyear": true,
+	"track":        true,
+	"year":         true,
+}
+
+// SortKey is a tag to sort by, and the direction of the sort.
+type SortKey struct {
+	Tag        string
+	Descending bool
+}
+
+// ParseSortKeys converts tag names into sort keys. Tag names prefixed with a
+// minus sign are sorted in descending order.
+func ParseSortKeys(fields []string) ([]SortKey, error) {
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("Cannot sort without sort criteria")
+	}
+	keys := make([]SortKey, len(fields))
+	for i, field := range fields {
+		field = strings.TrimSpace(field)
+		if strings.HasPrefix(field, "-") {
+			keys[i].Descending = true
+			field = field[1:]
+		}
+		if len(field) == 0 {
+			return nil, fmt.Errorf("Cannot sort by an empty tag name")
+		}
+		keys[i].Tag = strings.ToLower(field)
+	}
+	return keys, nil
+}
+
+// CompareSongs compares two songs by a set of sort keys, where the last key is
+// the most significant. The result is -1 if a sorts before b, 0 if they are
+// equal, and +1 if a sorts after b.
+func CompareSongs(a, b *song.Song, keys []SortKey) int {
+	for i := len(keys) - 1; i >= 0; i-- {
+		c := compareTag(a, b, keys[i].Tag)
+		if keys[i].Descending {
+			c = -c
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// GroupKey returns a string representation of a song's sort tags, suitable
+// for grouping songs with the same tag values. All values of tags with
+// several values are part of the key, and letter case is ignored.
+func GroupKey(s *song.Song, tags []string) string {
+	values := make([]string, len(tags))
+	for i, tag := range tags {
+		values[i] = s.SortTags[tag]
+	}
+	return strings.Join(values, "\x00")
+}
+
+// compareTag compares two songs by a single tag. The time tag is compared by
+// song duration, and numeric tags are compared in natural order.
+func compareTag(a, b *song.Song, tag string) int {
+	switch {
+	case tag == "time":
+		switch {
+		case a.Time < b.Time:
+			return -1
+		case a.Time > b.Time:
+			return 1
+		default:
+			return 0
+		}
+	case naturalSortTags[tag]:
+		return utils.NaturalCompare(a.SortTags[tag], b.SortTags[tag])
+	default:
+		return strings.Compare(a.SortTags[tag], b.SortTags[tag])
+	}
+}
@@ -0,0 +1,67 @@
+package songlist_test
+
+import (
+	"testing"
+
+	"github.com/ambientsound/gompd/mpd"
+	"github.com/ambientsound/pms/song"
+	"github.com/ambientsound/pms/songlist"
+	"github.com/stretchr/testify/assert"
+)
+
+var sortTests = []struct {
+	fields []string
+	files  []string
+}{
+	{[]string{"disc"}, []string{"a", "b", "c", "d"}},
+	{[]string{"-disc"}, []string{"d", "c", "a", "b"}},
+	{[]string{"time"}, []string{"c", "a", "d", "b"}},
+	{[]string{"-time"}, []string{"b", "d", "a", "c"}},
+	{[]string{"time", "date"}, []string{"b", "a", "c", "d"}},
+	{[]string{"title"}, []string{"a", "b", "c", "d"}},
+	{[]string{"-file", "title"}, []string{"b", "a", "d", "c"}},
+}
+
+func sortTestList() songlist.Songlist {
+	list := songlist.New()
+	for _, attrs := range []mpd.Attrs{
+		{"file": "a", "disc": "2", "time": "100", "date": "1999", "title": "x"},
+		{"file": "b", "disc": "2", "time": "300", "date": "199", "title": "x"},
+		{"file": "c", "disc": "10", "time": "60", "date": "2001-05", "title": "y"},
+		{"file": "d", "disc": "10/12", "time": "200", "date": "2001-10", "title": "y"},
+	} {
+		s := song.New()
+		s.SetTags(attrs)
+		list.Add(s)
+	}
+	return list
+}
+
+func TestSort(t *testing.T) {
+	for _, test := range sortTests {
+		list := sortTestList()
+		err := list.Sort(test.fields)
+		assert.Nil(t, err)
+
+		files := make([]string, 0, list.Len())
+		for _, s := range list.Songs() {
+			files = append(files, s.StringTags["file"])
+		}
+		assert.Equal(t, test.files, files, "sort by %v", test.fields)
+	}
+}
+
+func TestParseSortKeys(t *testing.T) {
+	keys, err := songlist.ParseSortKeys([]string{"-Year", "album"})
+	assert.Nil(t, err)
+	assert.Equal(t, []songlist.SortKey{
+		{Tag: "year", Descending: true},
+		{Tag: "album", Descending: false},
+	}, keys)
+
+	_, err = songlist.ParseSortKeys([]string{})
+	assert.NotNil(t, err)
+
+	_, err = songlist.ParseSortKeys([]string{"-"})
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,286 @@
+package songlist
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/ambientsound/gompd/mpd"
+	"github.com/ambientsound/pms/song"
+	"github.com/ambientsound/pms/utils"
+)
+
+// statsTopCount is the number of rows shown for each library aggregate.
+const statsTopCount = 10
+
+// Stats is a read-only Songlist which shows statistics about the MPD server
+// and the song library. Each statistic is represented by a song with the tags
+// 'section', 'name', 'count' and 'duration'. Rows with library aggregates can
+// be opened, giving a new songlist with the songs counted in that row.
+type Stats struct {
+	BaseSonglist
+	library *Library
+	filters []statsFilter
+}
+
+// statsFilter selects the songs counted in a row.
+type statsFilter struct {
+	name  string
+	match func(*song.Song) bool
+}
+
+// statsGroup holds the aggregated values of a group of songs.
+type statsGroup struct {
+	name     string
+	count    int
+	duration int
+}
+
+// NewStats returns Stats, showing the given MPD server statistics followed by
+// aggregates of the songs in the library. If the server statistics are nil,
+// they are not shown.
+func NewStats(server mpd.Attrs, library *Library) (s *Stats) {
+	s = &Stats{
+		library: library,
+		filters: make([]statsFilter, 0),
+	}
+	s.clear()
+
+	if server != nil {
+		s.addServerStats(server)
+	}
+
+	artists := groupValues(library.Songs(), "artist")
+	s.addGroups("Artists by tracks", "artist", byCount(artists))
+	s.addGroups("Artists by duration", "artist", byDuration(artists))
+
+	genres := groupValues(library.Songs(), "genre")
+	s.addGroups("Genres by tracks", "genre", byCount(genres))
+	s.addGroups("Genres by duration", "genre", byDuration(genres))
+
+	s.addDecades()
+
+	return
+}
+
+func (s *Stats) Name() string {
+	return "Statistics"
+}
+
+// DefaultColumns implements Columnar.
+func (s *Stats) DefaultColumns() []string {
+	return []string{"section", "name", "count", "duration"}
+}
+
+// Open implements Opener. It returns the library songs counted in a row.
+func (s *Stats) Open(index int) (Songlist, error) {
+	if index < 0 || index >= len(s.filters) {
+		return nil, fmt.Errorf("Out of bounds")
+	}
+	filter := s.filters[index]
+	if filter.match == nil {
+		return nil, fmt.Errorf("Only library statistics can be opened.")
+	}
+
+	list := NewSearch()
+	list.SetName(filter.name)
+	for _, song := range s.library.Songs() {
+		if filter.match(song) {
+			list.Add(song)
+		}
+	}
+
+	return list, nil
+}
+
+func (s *Stats) SetName(name string) error {
+	return fmt.Errorf("The statistics name cannot be changed.")
+}
+
+func (s *Stats) Clear() error {
+	return fmt.Errorf("The statistics are read-only.")
+}
+
+func (s *Stats) Add(song *song.Song) error {
+	return fmt.Errorf("The statistics are read-only.")
+}
+
+func (s *Stats) AddList(songlist Songlist) error {
+	return fmt.Errorf("The statistics are read-only.")
+}
+
+func (s *Stats) Insert(song *song.Song, position int) error {
+	return fmt.Errorf("The statistics are read-only.")
+}
+
+func (s *Stats) InsertList(songlist Songlist, position int) error {
+	return fmt.Errorf("The statistics are read-only.")
+}
+
+func (s *Stats) Remove(index int) error {
+	return fmt.Errorf("The statistics are read-only.")
+}
+
+func (s *Stats) RemoveIndices(indices []int) error {
+	return fmt.Errorf("The statistics are read-only.")
+}
+
+func (s *Stats) Reorder(order []int) error {
+	return fmt.Errorf("The statistics are read-only.")
+}
+
+func (s *Stats) Sort(fields []string) error {
+	return fmt.Errorf("The statistics are read-only.")
+}
+
+// addRow adds a single row of statistics.
+func (s *Stats) addRow(section, name, count, duration string, filter statsFilter) {
+	row := song.New()
+	row.SetTags(mpd.Attrs{
+		"section":  section,
+		"name":     name,
+		"count":    count,
+		"duration": duration,
+	})
+	s.add(row)
+	s.filters = append(s.filters, filter)
+}
+
+// addServerStats adds rows for the MPD server statistics.
+func (s *Stats) addServerStats(server mpd.Attrs) {
+	durations := []struct{ key, name string }{
+		{"uptime", "Uptime"},
+		{"playtime", "Playtime"},
+		{"db_playtime", "Database playtime"},
+	}
+	for _, d := range durations {
+		secs, err := strconv.Atoi(server[d.key])
+		if err != nil {
+			continue
+		}
+		s.addRow("Server", d.name, "", utils.TimeString(secs), statsFilter{})
+	}
+
+	counts := []struct{ key, name string }{
+		{"artists", "Artists"},
+		{"albums", "Albums"},
+		{"songs", "Songs"},
+	}
+	for _, c := range counts {
+		if len(server[c.key]) == 0 {
+			continue
+		}
+		s.addRow("Server", c.name, server[c.key], "", statsFilter{})
+	}
+}
+
+// addGroups adds a row for each of the first groups. Opening a row gives the
+// songs having the group name as one of the values of the tag.
+func (s *Stats) addGroups(section, tag string, groups []statsGroup) {
+	if len(groups) > statsTopCount {
+		groups = groups[:statsTopCount]
+	}
+	for _, group := range groups {
+		value := group.name
+		filter := statsFilter{
+			name: fmt.Sprintf("%s: %s", tag, value),
+			match: func(s *song.Song) bool {
+				return s.HasOneOfValues(tag, []string{value})
+			},
+		}
+		s.addRow(section, group.name, strconv.Itoa(group.count), utils.TimeString(group.duration), filter)
+	}
+}
+
+// addDecades adds a row for each decade, based on the year tag.
+func (s *Stats) addDecades() {
+	groups := make(map[int]*statsGroup)
+	for _, song := range s.library.Songs() {
+		decade, ok := songDecade(song)
+		if !ok {
+			continue
+		}
+		group, ok := groups[decade]
+		if !ok {
+			group = &statsGroup{name: fmt.Sprintf("%ds", decade)}
+			groups[decade] = group
+		}
+		group.count++
+		group.duration += song.Time
+	}
+
+	decades := make([]int, 0, len(groups))
+	for decade := range groups {
+		decades = append(decades, decade)
+	}
+	sort.Ints(decades)
+
+	for _, decade := range decades {
+		decade := decade
+		group := groups[decade]
+		filter := statsFilter{
+			name: fmt.Sprintf("Decade: %s", group.name),
+			match: func(s *song.Song) bool {
+				d, ok := songDecade(s)
+				return ok && d == decade
+			},
+		}
+		s.addRow("Decades", group.name, strconv.Itoa(group.count), utils.TimeString(group.duration), filter)
+	}
+}
+
+// songDecade returns the decade a song was released in, based on the year tag.
+func songDecade(s *song.Song) (int, bool) {
+	year, err := strconv.Atoi(s.StringTags["year"])
+	if err != nil {
+		return 0, false
+	}
+	return year / 10 * 10, true
+}
+
+// groupValues counts the songs and total duration for each value of a tag.
+// Songs with several values for the tag are counted once for each value.
+func groupValues(songs []*song.Song, tag string) []statsGroup {
+	groups := make(map[string]*statsGroup)
+	for _, s := range songs {
+		for _, value := range s.Values(tag) {
+			group, ok := groups[value]
+			if !ok {
+				group = &statsGroup{name: value}
+				groups[value] = group
+			}
+			group.count++
+			group.duration += s.Time
+		}
+	}
+
+	result := make([]statsGroup, 0, len(groups))
+	for _, group := range groups {
+		result = append(result, *group)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].name < result[j].name
+	})
+
+	return result
+}
+
+// byCount returns the groups sorted by descending song count.
+func byCount(groups []statsGroup) []statsGroup {
+	sorted := make([]statsGroup, len(groups))
+	copy(sorted, groups)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].count > sorted[j].count
+	})
+	return sorted
+}
+
+// byDuration returns the groups sorted by descending total duration.
+func byDuration(groups []statsGroup) []statsGroup {
+	sorted := make([]statsGroup, len(groups))
+	copy(sorted, groups)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].duration > sorted[j].duration
+	})
+	return sorted
+}
@@ -0,0 +1,88 @@
+package songlist_test
+
+import (
+	"testing"
+
+	"github.com/ambientsound/gompd/mpd"
+	"github.com/ambientsound/pms/song"
+	"github.com/ambientsound/pms/songlist"
+	"github.com/stretchr/testify/assert"
+)
+
+func statsLibrary() *songlist.Library {
+	library := songlist.NewLibrary()
+	for _, tags := range []song.MultiTaglist{
+		{"file": {"a"}, "artist": {"Foo"}, "genre": {"Rock"}, "date": {"1994"}, "time": {"100"}},
+		{"file": {"b"}, "artist": {"Foo", "Bar"}, "genre": {"Rock"}, "date": {"1999-02-01"}, "time": {"100"}},
+		{"file": {"c"}, "artist": {"Bar"}, "genre": {"Jazz"}, "date": {"2003"}, "time": {"500"}},
+		{"file": {"d"}, "artist": {"Baz"}, "time": {"10"}},
+	} {
+		s := song.New()
+		s.SetMultiTags(tags, nil)
+		library.Add(s)
+	}
+	return library
+}
+
+func statsRows(stats *songlist.Stats) [][]string {
+	rows := make([][]string, stats.Len())
+	for i, s := range stats.Songs() {
+		rows[i] = []string{s.StringTags["section"], s.StringTags["name"], s.StringTags["count"], s.StringTags["duration"]}
+	}
+	return rows
+}
+
+func TestStats(t *testing.T) {
+	server := mpd.Attrs{
+		"uptime":      "3725",
+		"playtime":    "60",
+		"db_playtime": "710",
+		"artists":     "3",
+		"albums":      "0",
+		"songs":       "4",
+	}
+	stats := songlist.NewStats(server, statsLibrary())
+
+	assert.Equal(t, [][]string{
+		{"Server", "Uptime", "", "1:02:05"},
+		{"Server", "Playtime", "", "01:00"},
+		{"Server", "Database playtime", "", "11:50"},
+		{"Server", "Artists", "3", ""},
+		{"Server", "Albums", "0", ""},
+		{"Server", "Songs", "4", ""},
+		{"Artists by tracks", "Bar", "2", "10:00"},
+		{"Artists by tracks", "Foo", "2", "03:20"},
+		{"Artists by tracks", "Baz", "1", "00:10"},
+		{"Artists by duration", "Bar", "2", "10:00"},
+		{"Artists by duration", "Foo", "2", "03:20"},
+		{"Artists by duration", "Baz", "1", "00:10"},
+		{"Genres by tracks", "Rock", "2", "03:20"},
+		{"Genres by tracks", "Jazz", "1", "08:20"},
+		{"Genres by duration", "Jazz", "1", "08:20"},
+		{"Genres by duration", "Rock", "2", "03:20"},
+		{"Decades", "1990s", "2", "03:20"},
+		{"Decades", "2000s", "1", "08:20"},
+	}, statsRows(stats))
+
+	// Server statistics cannot be opened.
+	_, err := stats.Open(0)
+	assert.NotNil(t, err)
+
+	// Library aggregates give the songs counted in the row.
+	list, err := stats.Open(6)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"b", "c"}, files(list))
+	assert.Equal(t, "artist: Bar", list.Name())
+
+	list, err = stats.Open(16)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"a", "b"}, files(list))
+
+	_, err = stats.Open(100)
+	assert.NotNil(t, err)
+}
+
+func TestStatsWithoutServer(t *testing.T) {
+	stats := songlist.NewStats(nil, songlist.NewLibrary())
+	assert.Equal(t, 0, stats.Len())
+}
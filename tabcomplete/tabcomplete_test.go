@@ -20,8 +20,10 @@ var tabCompleteTests = []struct {
 		"seek",
 		"select",
 		"set",
+		"shuffle",
 		"single",
 		"sort",
+		"stats",
 		"stop",
 		"style",
 	}},
@@ -0,0 +1,65 @@
+package topbar
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ambientsound/pms/api"
+)
+
+// Audio draws the audio format of the currently playing song.
+type Audio struct {
+	api api.API
+	raw bool
+}
+
+// NewAudio returns Audio.
+func NewAudio(a api.API, param string) Fragment {
+	return &Audio{a, param == `raw`}
+}
+
+// Text implements Fragment.
+func (w *Audio) Text() (string, string) {
+	playerStatus := w.api.PlayerStatus()
+	if w.raw {
+		return playerStatus.Audio, `audio`
+	}
+	return AudioFormatString(playerStatus.Audio), `audio`
+}
+
+// AudioFormatString converts MPD's audio format, given as
+// "samplerate:bits:channels", into a human readable string such as
+// "44.1 kHz, 16 bit, stereo". Unknown formats are returned verbatim.
+func AudioFormatString(format string) string {
+	parts := strings.Split(format, ":")
+	if len(parts) != 3 {
+		return format
+	}
+
+	rate, bits, channels := parts[0], parts[1], parts[2]
+
+	if hz, err := strconv.Atoi(rate); err == nil {
+		rate = strconv.FormatFloat(float64(hz)/1000, 'f', -1, 64) + " kHz"
+	}
+
+	switch bits {
+	case "f":
+		bits = "float"
+	default:
+		if _, err := strconv.Atoi(bits); err == nil {
+			bits += " bit"
+		}
+	}
+
+	switch channels {
+	case "1":
+		channels = "mono"
+	case "2":
+		channels = "stereo"
+	default:
+		channels = fmt.Sprintf("%s channels", channels)
+	}
+
+	return fmt.Sprintf("%s, %s, %s", rate, bits, channels)
+}
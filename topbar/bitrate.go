@@ -0,0 +1,26 @@
+package topbar
+
+import (
+	"fmt"
+
+	"github.com/ambientsound/pms/api"
+)
+
+// Bitrate draws the instantaneous bitrate of the currently playing song.
+type Bitrate struct {
+	api api.API
+}
+
+// NewBitrate returns Bitrate.
+func NewBitrate(a api.API, param string) Fragment {
+	return &Bitrate{a}
+}
+
+// Text implements Fragment.
+func (w *Bitrate) Text() (string, string) {
+	playerStatus := w.api.PlayerStatus()
+	if playerStatus.Bitrate <= 0 {
+		return ``, `bitrate`
+	}
+	return fmt.Sprintf("%d kbps", playerStatus.Bitrate), `bitrate`
+}
@@ -0,0 +1,29 @@
+package topbar
+
+import (
+	"time"
+
+	"github.com/ambientsound/pms/api"
+	"github.com/ambientsound/pms/utils"
+)
+
+// defaultClockFormat is used when no format is given to the clock fragment.
+const defaultClockFormat = "%H:%M"
+
+// Clock draws the current time using a strftime-style format.
+type Clock struct {
+	format string
+}
+
+// NewClock returns Clock.
+func NewClock(a api.API, param string) Fragment {
+	if len(param) == 0 {
+		param = defaultClockFormat
+	}
+	return &Clock{param}
+}
+
+// Text implements Fragment.
+func (w *Clock) Text() (string, string) {
+	return utils.Strftime(w.format, time.Now()), `clock`
+}
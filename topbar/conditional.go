@@ -0,0 +1,88 @@
+package topbar
+
+// value returns the raw value of a fragment.
+func value(frag Fragment) string {
+	if valuer, ok := frag.(Valuer); ok {
+		return valuer.Value()
+	}
+	text, _ := frag.Text()
+	return text
+}
+
+// True returns true if the condition is fulfilled. If no value is given in the
+// condition, it is true if the fragment has any value.
+func (c *ConditionStatement) True() bool {
+	v := value(c.Instance)
+	if len(c.Value) == 0 {
+		return len(v) > 0
+	}
+	return v == c.Value
+}
+
+// Resolve returns the first fragment in the chain of fallbacks that has a
+// value. If none of them have a value, the last fragment is returned.
+func (stmt *FragmentStatement) Resolve() *FragmentStatement {
+	for stmt.Fallback != nil && len(value(stmt.Instance)) == 0 {
+		stmt = stmt.Fallback
+	}
+	return stmt
+}
+
+// Text returns the text of the fragment, along with its stylesheet identifier.
+// Inline styles take precedence over the style provided by the fragment.
+func (stmt *FragmentStatement) Text() (string, string) {
+	text, style := stmt.Instance.Text()
+	if len(stmt.Style) > 0 {
+		style = stmt.Style
+	}
+	return text, style
+}
+
+// Stretch returns the segments of a stretchable fragment, with any inline
+// style applied. If the fragment is not stretchable, nil is returned.
+func (stmt *FragmentStatement) Stretch(width int) []Segment {
+	stretchable, ok := stmt.Instance.(Stretchable)
+	if !ok {
+		return nil
+	}
+	segments := stretchable.Stretch(width)
+	if len(stmt.Style) > 0 {
+		for i := range segments {
+			segments[i].Style = stmt.Style
+		}
+	}
+	return segments
+}
+
+// Visible returns the fragments that should be drawn, according to the
+// conditionals in the piece. Fallbacks are resolved.
+func (piece *PieceStatement) Visible() []*FragmentStatement {
+	visible := make([]*FragmentStatement, 0, len(piece.Fragments))
+
+	// The stack holds the visibility of all enclosing conditionals.
+	stack := make([]bool, 0)
+	shown := true
+
+	for _, stmt := range piece.Fragments {
+		switch stmt.Variable {
+		case ifVariable:
+			stack = append(stack, shown)
+			shown = shown && stmt.Condition.True()
+		case elseVariable:
+			if len(stack) > 0 {
+				shown = stack[len(stack)-1] && !shown
+			}
+		case endifVariable:
+			if len(stack) > 0 {
+				shown = stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+			}
+		default:
+			if shown {
+				visible = append(visible, stmt.Resolve())
+			}
+		}
+	}
+
+	return visible
+}
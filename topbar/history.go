@@ -0,0 +1,41 @@
+package topbar
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ambientsound/pms/api"
+)
+
+// History draws information about the listening history.
+type History struct {
+	api api.API
+	f   func() (string, string)
+}
+
+// NewHistory returns History.
+func NewHistory(a api.API, param string) Fragment {
+	history := &History{a, nil}
+	switch param {
+	case `total`:
+		history.f = history.textTotal
+	default:
+		history.f = history.textToday
+	}
+	return history
+}
+
+// Text implements Fragment.
+func (w *History) Text() (string, string) {
+	return w.f()
+}
+
+func (w *History) textToday() (string, string) {
+	history := w.api.Db().History()
+	return fmt.Sprintf("%d", history.PlayedOn(time.Now())), `historyToday`
+}
+
+func (w *History) textTotal() (string, string) {
+	history := w.api.Db().History()
+	return fmt.Sprintf("%d", history.Len()), `historyTotal`
+}
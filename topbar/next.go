@@ -0,0 +1,62 @@
+package topbar
+
+import (
+	"github.com/ambientsound/pms/api"
+	"github.com/ambientsound/pms/song"
+)
+
+// Next draws song tags from the song that will be played after the current song.
+type Next struct {
+	api api.API
+	tag string
+}
+
+// NewNext returns Next.
+func NewNext(a api.API, param string) Fragment {
+	return &Next{a, param}
+}
+
+// Text implements Fragment.
+func (w *Next) Text() (string, string) {
+	next := w.song()
+	if next == nil {
+		return `<none>`, `tagMissing`
+	}
+	if text, ok := next.StringTags[w.tag]; ok {
+		return text, w.tag
+	}
+	return `<unknown>`, `tagMissing`
+}
+
+// song returns the next song in the queue, as reported by MPD, or nil if
+// there is no next song.
+func (w *Next) song() *song.Song {
+	queue := w.api.Queue()
+	if queue == nil {
+		return nil
+	}
+
+	playerStatus := w.api.PlayerStatus()
+	if playerStatus.NextSongID != song.NullID {
+		for _, s := range queue.Songs() {
+			if s.ID == playerStatus.NextSongID {
+				return s
+			}
+		}
+	}
+
+	if playerStatus.NextSong != song.NullPosition {
+		return queue.Song(playerStatus.NextSong)
+	}
+
+	return nil
+}
+
+// Value implements Valuer.
+func (w *Next) Value() string {
+	next := w.song()
+	if next == nil {
+		return ``
+	}
+	return next.StringTags[w.tag]
+}
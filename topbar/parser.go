@@ -3,11 +3,22 @@ package topbar
 import (
 	"fmt"
 	"io"
+	"strings"
+	"unicode"
 
 	"github.com/ambientsound/pms/input/lexer"
 	"github.com/ambientsound/pms/parser"
 )
 
+// Keywords and operators in the topbar syntax.
+const (
+	ifVariable       = "if"
+	elseVariable     = "else"
+	endifVariable    = "endif"
+	fallbackOperator = "?:"
+	styleMarker      = ":style"
+)
+
 // Parser represents a parser.
 type Parser struct {
 	parser.Parser
@@ -22,11 +33,27 @@ func NewParser(r io.Reader) *Parser {
 //
 // ${variable|param}
 // or:
+// ${variable|param:style=name ?: variable|param}
+// or:
 // frag2
 type FragmentStatement struct {
-	Literal  string
+	Literal   string
+	Variable  string
+	Param     string
+	Instance  Fragment
+	Style     string
+	Fallback  *FragmentStatement
+	Condition *ConditionStatement
+}
+
+// ConditionStatement holds information about the condition in an if
+// statement, e.g.:
+//
+// ${if variable|param=value}
+type ConditionStatement struct {
 	Variable string
 	Param    string
+	Value    string
 	Instance Fragment
 }
 
@@ -93,45 +120,175 @@ func (p *Parser) ParseFragment() (*FragmentStatement, error) {
 	if tok != lexer.TokenIdentifier {
 		return nil, fmt.Errorf("Unexpected %v, expected identifier", lit)
 	}
-	stmt.Variable = lit
 
-	// Next, we can either have a separator in order to pass parameters, or
-	// close with a curly bracket.
-	tok, lit = p.ScanIgnoreWhitespace()
+	// Conditionals have their own syntax.
+	if lit == ifVariable {
+		if err := p.parseCondition(stmt); err != nil {
+			return nil, err
+		}
+		return stmt, nil
+	}
+
+	// Parse the variable, and any fallback variables separated by '?:'.
+	current := stmt
+	for {
+		var err error
+		tok, lit, err = p.parseVariable(current, lit)
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		// Finished parsing the variable.
+		case tok == lexer.TokenClose:
+			return stmt, nil
+
+		// Fallback variable, e.g. '${tag|albumartist ?: tag|artist}'.
+		case tok == lexer.TokenIdentifier && lit == fallbackOperator:
+			tok, lit = p.ScanIgnoreWhitespace()
+			if tok != lexer.TokenIdentifier {
+				return nil, fmt.Errorf("Unexpected %v, expected identifier after '%s'", lit, fallbackOperator)
+			}
+			current.Fallback = &FragmentStatement{}
+			current = current.Fallback
+
+		// No other tokens are valid.
+		default:
+			return nil, fmt.Errorf("Unexpected %v, expected '|' or '}'", lit)
+		}
+	}
+}
+
+// parseVariable parses a variable name, an optional parameter, and an optional
+// inline style, e.g. 'tag|title:style=title'. The token following the
+// variable is returned.
+func (p *Parser) parseVariable(stmt *FragmentStatement, variable string) (int, string, error) {
+	var styled bool
+
+	stmt.Variable, styled = splitStyle(variable)
+	tok, lit := p.ScanIgnoreWhitespace()
 
-	switch tok {
 	// Parameterized variable, e.g. '${tag|artist}'.
-	case lexer.TokenSeparator:
-		break
+	if !styled && tok == lexer.TokenSeparator {
+		var err error
+		stmt.Param, stmt.Style, styled, err = p.parseParam(stmt.Variable)
+		if err != nil {
+			return tok, lit, err
+		}
+		tok, lit = p.ScanIgnoreWhitespace()
+	}
 
-	// Finished parsing the variable.
-	case lexer.TokenClose:
-		return stmt, nil
+	// Inline style, e.g. '${tag|title:style=title}'.
+	if styled {
+		if tok != lexer.TokenEqual {
+			return tok, lit, fmt.Errorf("Unexpected %v, expected '='", lit)
+		}
+		tok, lit = p.ScanIgnoreWhitespace()
+		if tok != lexer.TokenIdentifier {
+			return tok, lit, fmt.Errorf("Unexpected %v, expected style name", lit)
+		}
+		stmt.Style = lit
+		tok, lit = p.ScanIgnoreWhitespace()
+	}
 
-	// No other tokens are valid.
-	default:
-		return nil, fmt.Errorf("Unexpected %v, expected '|' or '}'", lit)
+	return tok, lit, nil
+}
+
+// parseParam reads a variable parameter as-is, so that parameters such as
+// progress bar glyphs and clock formats may contain any character. The
+// parameter ends at whitespace or '}', unless enclosed in double quotes. An
+// inline style directly following the parameter is returned separately. If
+// the parameter ends with a style marker, the style name is left for the
+// caller to parse.
+func (p *Parser) parseParam(variable string) (param, style string, styled bool, err error) {
+	quoted := false
+	started := false
+	raw := p.ScanUntil(func(r rune) bool {
+		switch {
+		case r == '"':
+			quoted = !quoted
+		case quoted:
+		case unicode.IsSpace(r):
+			return started
+		case r == '}':
+			return true
+		}
+		started = true
+		return false
+	})
+	raw = strings.TrimSpace(raw)
+
+	if i := strings.LastIndex(raw, styleMarker+"="); i >= 0 {
+		raw, style = raw[:i], raw[i+len(styleMarker)+1:]
+		if len(style) == 0 {
+			return "", "", false, fmt.Errorf("Unexpected '}', expected style name")
+		}
+	} else {
+		raw, styled = splitStyle(raw)
 	}
 
-	// The only valid token is the parameter.
-	tok, lit = p.ScanIgnoreWhitespace()
+	if len(raw) >= 2 && strings.HasPrefix(raw, `"`) && strings.HasSuffix(raw, `"`) {
+		raw = raw[1 : len(raw)-1]
+	}
+	if len(raw) == 0 {
+		return "", "", false, fmt.Errorf("Missing parameter to $%s", variable)
+	}
+
+	return raw, style, styled, nil
+}
+
+// parseCondition parses the remainder of an if statement, e.g.
+// 'state=play}' or 'tag|artist}'.
+func (p *Parser) parseCondition(stmt *FragmentStatement) error {
+	stmt.Variable = ifVariable
+	stmt.Condition = &ConditionStatement{}
+
+	tok, lit := p.ScanIgnoreWhitespace()
 	if tok != lexer.TokenIdentifier {
-		return nil, fmt.Errorf("Unexpected %v, expected parameter to $%s", lit, stmt.Variable)
+		return fmt.Errorf("Unexpected %v, expected condition", lit)
 	}
-	stmt.Param = lit
+	stmt.Condition.Variable = lit
 
-	// The only valid token is the closing curly bracket.
 	tok, lit = p.ScanIgnoreWhitespace()
+	if tok == lexer.TokenSeparator {
+		tok, lit = p.ScanIgnoreWhitespace()
+		if tok != lexer.TokenIdentifier {
+			return fmt.Errorf("Unexpected %v, expected parameter to $%s", lit, stmt.Condition.Variable)
+		}
+		stmt.Condition.Param = lit
+		tok, lit = p.ScanIgnoreWhitespace()
+	}
+
+	if tok == lexer.TokenEqual {
+		tok, lit = p.ScanIgnoreWhitespace()
+		if tok != lexer.TokenIdentifier {
+			return fmt.Errorf("Unexpected %v, expected value", lit)
+		}
+		stmt.Condition.Value = lit
+		tok, lit = p.ScanIgnoreWhitespace()
+	}
+
 	if tok != lexer.TokenClose {
-		return nil, fmt.Errorf("Unexpected %v, expected '}'", lit)
+		return fmt.Errorf("Unexpected %v, expected '}'", lit)
 	}
 
-	return stmt, nil
+	return nil
 }
 
-// ParsePiece parses a piece statement.
+// splitStyle strips the inline style marker from an identifier, and returns
+// true if it was present.
+func splitStyle(lit string) (string, bool) {
+	if strings.HasSuffix(lit, styleMarker) {
+		return strings.TrimSuffix(lit, styleMarker), true
+	}
+	return lit, false
+}
+
+// ParsePiece parses a piece statement. Conditionals must be closed within
+// the same piece.
 func (p *Parser) ParsePiece() (*PieceStatement, error) {
 	stmt := &PieceStatement{}
+	depth := 0
 
 	for {
 		tok, _ := p.Scan()
@@ -142,6 +299,9 @@ func (p *Parser) ParsePiece() (*PieceStatement, error) {
 			p.Unscan()
 			fallthrough
 		case lexer.TokenSeparator, lexer.TokenEnd:
+			if depth > 0 {
+				return nil, fmt.Errorf("Missing ${%s}", endifVariable)
+			}
 			return stmt, nil
 		}
 
@@ -151,6 +311,18 @@ func (p *Parser) ParsePiece() (*PieceStatement, error) {
 			return nil, err
 		}
 
+		switch frag.Variable {
+		case ifVariable:
+			depth++
+		case elseVariable, endifVariable:
+			if depth == 0 {
+				return nil, fmt.Errorf("Unexpected ${%s} without ${%s}", frag.Variable, ifVariable)
+			}
+			if frag.Variable == endifVariable {
+				depth--
+			}
+		}
+
 		stmt.Fragments = append(stmt.Fragments, frag)
 	}
 }
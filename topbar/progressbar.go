@@ -0,0 +1,114 @@
+package topbar
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ambientsound/pms/api"
+	"github.com/ambientsound/pms/utils"
+)
+
+// progressbarPresets are named glyph sets for the progress bar.
+var progressbarPresets = map[string]string{
+	"":        "=>-",
+	"ascii":   "[=>-]",
+	"unicode": "█▌░",
+}
+
+// Progressbar draws the progress of the current song as a bar which fills the
+// available width.
+type Progressbar struct {
+	api    api.API
+	left   string
+	filled string
+	head   string
+	empty  string
+	right  string
+}
+
+// NewProgressbar returns Progressbar. The parameter is either the name of a
+// preset, or a glyph specification. Glyph specifications are given as
+// "filled empty", "filled head empty", or "left filled head empty right".
+func NewProgressbar(a api.API, param string) Fragment {
+	if preset, ok := progressbarPresets[param]; ok {
+		param = preset
+	}
+
+	glyphs := make([]string, 0, 5)
+	for _, r := range param {
+		glyphs = append(glyphs, string(r))
+	}
+
+	w := &Progressbar{api: a}
+
+	switch len(glyphs) {
+	case 2:
+		w.filled, w.empty = glyphs[0], glyphs[1]
+	case 3:
+		w.filled, w.head, w.empty = glyphs[0], glyphs[1], glyphs[2]
+	case 5:
+		w.left, w.filled, w.head, w.empty, w.right = glyphs[0], glyphs[1], glyphs[2], glyphs[3], glyphs[4]
+	default:
+		return NewText(fmt.Sprintf("<invalid progressbar glyphs '%s'>", param))
+	}
+
+	return w
+}
+
+// Text implements Fragment. The progress bar has no natural width, and is only
+// drawn using Stretch.
+func (w *Progressbar) Text() (string, string) {
+	return ``, `progressEmpty`
+}
+
+// Stretch implements Stretchable.
+func (w *Progressbar) Stretch(width int) []Segment {
+	width -= utils.StringWidth(w.left) + utils.StringWidth(w.right)
+	if width <= 0 {
+		return nil
+	}
+
+	playerStatus := w.api.PlayerStatus()
+	filled := int(float64(width) * playerStatus.ElapsedPercentage / 100)
+	if filled < 0 {
+		filled = 0
+	} else if filled > width {
+		filled = width
+	}
+
+	head := ``
+	if filled+utils.StringWidth(w.head) <= width && playerStatus.Time > 0 && len(w.head) > 0 {
+		head = w.head
+	}
+
+	empty := width - filled - utils.StringWidth(head)
+
+	return []Segment{
+		{w.left + repeatWidth(w.filled, filled) + head, `progressFilled`},
+		{repeatWidth(w.empty, empty) + w.right, `progressEmpty`},
+	}
+}
+
+// Click implements Clickable, and seeks to the clicked position in the song.
+func (w *Progressbar) Click(offset, width int) string {
+	offset -= utils.StringWidth(w.left)
+	width -= utils.StringWidth(w.left) + utils.StringWidth(w.right)
+	if offset < 0 || offset >= width {
+		return ""
+	}
+	playerStatus := w.api.PlayerStatus()
+	if playerStatus.Time <= 0 {
+		return ""
+	}
+	return fmt.Sprintf("seek %d", playerStatus.Time*offset/width)
+}
+
+// repeatWidth repeats a glyph so that it fills the given number of cells. If
+// the glyph is wide, any remaining cell is filled with a space.
+func repeatWidth(glyph string, width int) string {
+	glyphWidth := utils.StringWidth(glyph)
+	if glyphWidth == 0 || width <= 0 {
+		return ``
+	}
+	return strings.Repeat(glyph, width/glyphWidth) + strings.Repeat(` `, width%glyphWidth)
+}
@@ -0,0 +1,79 @@
+package topbar
+
+import (
+	"fmt"
+
+	"github.com/ambientsound/pms/api"
+	"github.com/ambientsound/pms/mpd"
+	"github.com/ambientsound/pms/utils"
+)
+
+// Queue draws information about the MPD queue.
+type Queue struct {
+	api api.API
+	f   func() (string, string)
+}
+
+// NewQueue returns Queue.
+func NewQueue(a api.API, param string) Fragment {
+	queue := &Queue{a, nil}
+	switch param {
+	case `duration`:
+		queue.f = queue.textDuration
+	case `remaining`:
+		queue.f = queue.textRemaining
+	default:
+		queue.f = queue.textLength
+	}
+	return queue
+}
+
+// Text implements Fragment.
+func (w *Queue) Text() (string, string) {
+	return w.f()
+}
+
+func (w *Queue) textLength() (string, string) {
+	queue := w.api.Queue()
+	if queue == nil {
+		return `0`, `queueLength`
+	}
+	return fmt.Sprintf("%d", queue.Len()), `queueLength`
+}
+
+func (w *Queue) textDuration() (string, string) {
+	queue := w.api.Queue()
+	if queue == nil {
+		return utils.TimeString(0), `queueDuration`
+	}
+	total := 0
+	for _, s := range queue.Songs() {
+		total += s.Time
+	}
+	return utils.TimeString(total), `queueDuration`
+}
+
+// textRemaining draws the time left until the queue has finished playing,
+// counting from the current position in the currently playing song.
+func (w *Queue) textRemaining() (string, string) {
+	queue := w.api.Queue()
+	if queue == nil {
+		return utils.TimeString(0), `queueDuration`
+	}
+
+	playerStatus := w.api.PlayerStatus()
+	start := 0
+	elapsed := 0
+	if playerStatus.State != mpd.StateStop && queue.InRange(playerStatus.Song) {
+		start = playerStatus.Song
+		elapsed = int(playerStatus.Elapsed)
+	}
+
+	remaining := -elapsed
+	songs := queue.Songs()
+	for _, s := range songs[start:] {
+		remaining += s.Time
+	}
+
+	return utils.TimeString(utils.Max(0, remaining)), `queueDuration`
+}
@@ -39,3 +39,9 @@ func (w *State) Text() (string, string) {
 	playerStatus := w.api.PlayerStatus()
 	return w.table[playerStatus.State], `state`
 }
+
+// Value implements Valuer, and returns the player state as reported by MPD,
+// e.g. "play", "pause" or "stop".
+func (w *State) Value() string {
+	return w.api.PlayerStatus().State
+}
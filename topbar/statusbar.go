@@ -0,0 +1,175 @@
+package topbar
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/ambientsound/pms/api"
+	"github.com/ambientsound/pms/message"
+	"github.com/ambientsound/pms/utils"
+)
+
+// statusbarFragments holds fragments that can only be drawn in the statusbar,
+// in addition to all the topbar fragments.
+var statusbarFragments = map[string]func(api.API, string) Fragment{
+	"message":   NewMessage,
+	"readout":   NewReadout,
+	"selection": NewSelection,
+	"sequence":  NewSequence,
+}
+
+func init() {
+	for key, ctor := range fragments {
+		statusbarFragments[key] = ctor
+	}
+}
+
+// ParseStatusbar works like Parse, but also makes the statusbar fragments
+// available.
+func ParseStatusbar(a api.API, input string) (*MatrixStatement, error) {
+	return parse(a, input, statusbarFragments)
+}
+
+// Message draws the most recent message, or a visual mode indicator.
+type Message struct {
+	api api.API
+}
+
+// NewMessage returns Message.
+func NewMessage(a api.API, param string) Fragment {
+	return &Message{a}
+}
+
+// Text implements Fragment.
+func (w *Message) Text() (string, string) {
+	msg := w.api.Multibar().Message()
+	switch {
+	case len(msg.Text) == 0 && w.api.Songlist().HasVisualSelection():
+		return `-- VISUAL --`, `visualText`
+	case msg.Severity == message.Error:
+		return msg.Text, `errorText`
+	default:
+		return msg.Text, `statusbar`
+	}
+}
+
+// Value implements Valuer. The visual mode indicator is not considered a value.
+func (w *Message) Value() string {
+	return w.api.Multibar().Message().Text
+}
+
+// Readout draws the cursor position and the visible part of the songlist.
+type Readout struct {
+	api api.API
+	f   func() string
+}
+
+// NewReadout returns Readout.
+func NewReadout(a api.API, param string) Fragment {
+	readout := &Readout{a, nil}
+	switch param {
+	case `long`:
+		readout.f = readout.textLong
+	case `short`:
+		readout.f = readout.textShort
+	default:
+		readout.f = readout.textBoth
+	}
+	return readout
+}
+
+// Text implements Fragment.
+func (w *Readout) Text() (string, string) {
+	return w.f(), `readout`
+}
+
+// textBoth returns a combination of textLong and textShort.
+func (w *Readout) textBoth() string {
+	return fmt.Sprintf("%s    %s", w.textLong(), w.textShort())
+}
+
+// textLong returns a formatted string containing the cursor position, the
+// visible song range, and the total number of songs.
+func (w *Readout) textLong() string {
+	list := w.api.Songlist()
+	ymin, ymax := w.api.SonglistWidget().GetVisibleBoundaries()
+	return fmt.Sprintf("%d,%d-%d/%d", list.Cursor()+1, ymin+1, ymax+1, list.Len())
+}
+
+// textShort returns a percentage indicator on how far the songlist is scrolled.
+func (w *Readout) textShort() string {
+	list := w.api.Songlist()
+	ymin, ymax := w.api.SonglistWidget().GetVisibleBoundaries()
+	if ymin == 0 && ymax+1 == list.Len() {
+		return `All`
+	}
+	if ymin == 0 {
+		return `Top`
+	}
+	if ymax+1 == list.Len() {
+		return `Bot`
+	}
+	fraction := float64(float64(ymin) / float64(list.Len()))
+	percent := int(math.Floor(fraction * 100))
+	return fmt.Sprintf("%2d%%", percent)
+}
+
+// Selection draws information about the selected songs in the current
+// songlist. Nothing is drawn when there is no selection.
+type Selection struct {
+	api api.API
+	f   func(count, duration int) (string, string)
+}
+
+// NewSelection returns Selection.
+func NewSelection(a api.API, param string) Fragment {
+	selection := &Selection{a, nil}
+	switch param {
+	case `duration`:
+		selection.f = selection.textDuration
+	default:
+		selection.f = selection.textCount
+	}
+	return selection
+}
+
+// Text implements Fragment.
+func (w *Selection) Text() (string, string) {
+	list := w.api.Songlist()
+	count := 0
+	duration := 0
+	for i := 0; i < list.Len(); i++ {
+		if list.Selected(i) {
+			count++
+			duration += list.Song(i).Time
+		}
+	}
+	if count == 0 {
+		return ``, `selectionCount`
+	}
+	return w.f(count, duration)
+}
+
+func (w *Selection) textCount(count, duration int) (string, string) {
+	return fmt.Sprintf("%d selected", count), `selectionCount`
+}
+
+func (w *Selection) textDuration(count, duration int) (string, string) {
+	return utils.TimeString(duration), `selectionDuration`
+}
+
+// Sequence draws the key sequence that has been typed so far, but has not
+// yet matched a key binding.
+type Sequence struct {
+	api api.API
+}
+
+// NewSequence returns Sequence.
+func NewSequence(a api.API, param string) Fragment {
+	return &Sequence{a}
+}
+
+// Text implements Fragment.
+func (w *Sequence) Text() (string, string) {
+	return w.api.Sequencer().String(), `sequenceText`
+}
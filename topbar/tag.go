@@ -26,3 +26,12 @@ func (w *Tag) Text() (string, string) {
 	}
 	return `<unknown>`, `tagMissing`
 }
+
+// Value implements Valuer.
+func (w *Tag) Value() string {
+	song := w.api.Song()
+	if song == nil {
+		return ``
+	}
+	return song.StringTags[w.tag]
+}
@@ -15,25 +15,77 @@ type Fragment interface {
 	Text() (string, string)
 }
 
+// Valuer is implemented by fragments whose raw value differs from their
+// drawn text. The value is used when evaluating conditionals and fallbacks,
+// where an empty value means that the fragment has no value. Fragments that
+// do not implement Valuer use their text as their value.
+type Valuer interface {
+	Value() string
+}
+
+// Segment is a piece of text along with its stylesheet identifier.
+type Segment struct {
+	Text  string
+	Style string
+}
+
+// Stretchable is implemented by fragments that fill the space left over in
+// their piece after all other fragments have been drawn.
+type Stretchable interface {
+
+	// Stretch returns the segments that should be drawn in order to fill
+	// exactly the given width.
+	Stretch(width int) []Segment
+}
+
+// Clickable is implemented by fragments that react to mouse clicks.
+type Clickable interface {
+
+	// Click returns a command that should be run when the fragment is
+	// clicked, given the click offset within the drawn text and the width of
+	// the drawn text. An empty string means no action.
+	Click(offset, width int) string
+}
+
 // fragments is a map of fragments that can be drawn in the topbar, along with
 // their textual representation. When implementing a new topbar fragment, place
 // its constructor in this map.
 var fragments = map[string]func(api.API, string) Fragment{
-	"elapsed":   NewElapsed,
-	"list":      NewList,
-	"mode":      NewMode,
-	"shortname": NewShortname,
-	"state":     NewState,
-	"tag":       NewTag,
-	"time":      NewTime,
-	"version":   NewVersion,
-	"volume":    NewVolume,
+	"audio":       NewAudio,
+	"bitrate":     NewBitrate,
+	"clock":       NewClock,
+	"elapsed":     NewElapsed,
+	"history":     NewHistory,
+	"list":        NewList,
+	"mode":        NewMode,
+	"next":        NewNext,
+	"progressbar": NewProgressbar,
+	"queue":       NewQueue,
+	"shortname":   NewShortname,
+	"state":       NewState,
+	"tag":         NewTag,
+	"time":        NewTime,
+	"version":     NewVersion,
+	"volume":      NewVolume,
 }
 
 // NewFragment constructs a new Fragment based on a parsed topbar fragment statement.
 func NewFragment(a api.API, stmt *FragmentStatement) (Fragment, error) {
-	if len(stmt.Variable) == 0 {
+	return newFragment(a, stmt, fragments)
+}
+
+// newFragment constructs a new Fragment using the given set of fragments.
+func newFragment(a api.API, stmt *FragmentStatement, fragments map[string]func(api.API, string) Fragment) (Fragment, error) {
+	switch stmt.Variable {
+	case ``:
 		return NewText(stmt.Literal), nil
+	case elseVariable, endifVariable:
+		return NewText(``), nil
+	case ifVariable:
+		if stmt.Condition == nil {
+			return nil, fmt.Errorf("Missing condition in ${%s}", ifVariable)
+		}
+		return NewText(``), nil
 	}
 	if ctor, ok := fragments[stmt.Variable]; ok {
 		return ctor(a, stmt.Param), nil
@@ -44,6 +96,12 @@ func NewFragment(a api.API, stmt *FragmentStatement) (Fragment, error) {
 // Parse sets up a lexer and parser for a topbar matrix statement, instantiates
 // fragments, and returns the parse tree.
 func Parse(a api.API, input string) (*MatrixStatement, error) {
+	return parse(a, input, fragments)
+}
+
+// parse parses a matrix statement and instantiates fragments from the given
+// set of fragments.
+func parse(a api.API, input string, fragments map[string]func(api.API, string) Fragment) (*MatrixStatement, error) {
 	reader := strings.NewReader(input)
 	parser := NewParser(reader)
 
@@ -56,14 +114,37 @@ func Parse(a api.API, input string) (*MatrixStatement, error) {
 	for _, rowStmt := range matrixStmt.Rows {
 		for _, pieceStmt := range rowStmt.Pieces {
 			for _, fragmentStmt := range pieceStmt.Fragments {
-				frag, err := NewFragment(a, fragmentStmt)
-				if err != nil {
+				if err := instantiate(a, fragmentStmt, fragments); err != nil {
 					return nil, err
 				}
-				fragmentStmt.Instance = frag
 			}
 		}
 	}
 
 	return matrixStmt, nil
 }
+
+// instantiate instantiates a fragment, its fallbacks, and its condition.
+func instantiate(a api.API, stmt *FragmentStatement, fragments map[string]func(api.API, string) Fragment) error {
+	for ; stmt != nil; stmt = stmt.Fallback {
+		frag, err := newFragment(a, stmt, fragments)
+		if err != nil {
+			return err
+		}
+		stmt.Instance = frag
+
+		if stmt.Condition == nil {
+			continue
+		}
+
+		condition := &FragmentStatement{
+			Variable: stmt.Condition.Variable,
+			Param:    stmt.Condition.Param,
+		}
+		stmt.Condition.Instance, err = newFragment(a, condition, fragments)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -1,9 +1,15 @@
 package topbar_test
 
 import (
+	"fmt"
 	"strings"
 	"testing"
 
+	"github.com/ambientsound/gompd/mpd"
+	"github.com/ambientsound/pms/api"
+	pms_mpd "github.com/ambientsound/pms/mpd"
+	"github.com/ambientsound/pms/song"
+	"github.com/ambientsound/pms/songlist"
 	"github.com/ambientsound/pms/topbar"
 	"github.com/stretchr/testify/assert"
 )
@@ -14,14 +20,24 @@ var fragmentTests = []struct {
 	statement topbar.FragmentStatement
 }{
 	// Valid forms
-	{`plain`, true, topbar.FragmentStatement{`plain`, ``, ``, nil}},
-	{`plain; and more`, true, topbar.FragmentStatement{`plain`, ``, ``, nil}},
-	{`     |    `, true, topbar.FragmentStatement{`     `, ``, ``, nil}},
-	{`foo;bar`, true, topbar.FragmentStatement{`foo`, ``, ``, nil}},
-	{`$var`, true, topbar.FragmentStatement{``, `var`, ``, nil}},
-	{`${var}`, true, topbar.FragmentStatement{``, `var`, ``, nil}},
-	{`${var|param}`, true, topbar.FragmentStatement{``, `var`, `param`, nil}},
-	{`${  var  |  param  }`, true, topbar.FragmentStatement{``, `var`, `param`, nil}},
+	{`plain`, true, topbar.FragmentStatement{Literal: `plain`}},
+	{`plain; and more`, true, topbar.FragmentStatement{Literal: `plain`}},
+	{`     |    `, true, topbar.FragmentStatement{Literal: `     `}},
+	{`foo;bar`, true, topbar.FragmentStatement{Literal: `foo`}},
+	{`$var`, true, topbar.FragmentStatement{Variable: `var`}},
+	{`${var}`, true, topbar.FragmentStatement{Variable: `var`}},
+	{`${var|param}`, true, topbar.FragmentStatement{Variable: `var`, Param: `param`}},
+	{`${  var  |  param  }`, true, topbar.FragmentStatement{Variable: `var`, Param: `param`}},
+	{`${var:style=foo}`, true, topbar.FragmentStatement{Variable: `var`, Style: `foo`}},
+	{`${var|[=>-]}`, true, topbar.FragmentStatement{Variable: `var`, Param: `[=>-]`}},
+	{`${var|#.:style=foo}`, true, topbar.FragmentStatement{Variable: `var`, Param: `#.`, Style: `foo`}},
+	{`${var|"a b"}`, true, topbar.FragmentStatement{Variable: `var`, Param: `a b`}},
+	{`${var|param:style = foo}`, true, topbar.FragmentStatement{Variable: `var`, Param: `param`, Style: `foo`}},
+	{`${var|param ?: other}`, true, topbar.FragmentStatement{Variable: `var`, Param: `param`, Fallback: &topbar.FragmentStatement{Variable: `other`}}},
+	{`${a ?: b|c:style=d ?: e}`, true, topbar.FragmentStatement{Variable: `a`, Fallback: &topbar.FragmentStatement{Variable: `b`, Param: `c`, Style: `d`, Fallback: &topbar.FragmentStatement{Variable: `e`}}}},
+	{`${if state=play}`, true, topbar.FragmentStatement{Variable: `if`, Condition: &topbar.ConditionStatement{Variable: `state`, Value: `play`}}},
+	{`${if tag|artist}`, true, topbar.FragmentStatement{Variable: `if`, Condition: &topbar.ConditionStatement{Variable: `tag`, Param: `artist`}}},
+	{`${endif}`, true, topbar.FragmentStatement{Variable: `endif`}},
 
 	// Invalid forms
 	{`${var`, false, topbar.FragmentStatement{}},
@@ -33,6 +49,13 @@ var fragmentTests = []struct {
 	{`${{`, false, topbar.FragmentStatement{}},
 	{`${$`, false, topbar.FragmentStatement{}},
 	{`${   }`, false, topbar.FragmentStatement{}},
+	{`${var:style}`, false, topbar.FragmentStatement{}},
+	{`${var:style=}`, false, topbar.FragmentStatement{}},
+	{`${var ?:}`, false, topbar.FragmentStatement{}},
+	{`${var other}`, false, topbar.FragmentStatement{}},
+	{`${if}`, false, topbar.FragmentStatement{}},
+	{`${if state=}`, false, topbar.FragmentStatement{}},
+	{`${if state play}`, false, topbar.FragmentStatement{}},
 }
 
 func TestFragments(t *testing.T) {
@@ -69,9 +92,15 @@ var pieceTests = []struct {
 	{`hax | piece`, true, 2},
 	{`hax  ; more`, true, 2},
 	{`|||||`, true, 0},
+	{`${if state}a${else}b${endif}`, true, 5},
+	{`${if a}${if b}c${endif}${endif}`, true, 5},
 
 	// Invalid form
 	{`token plus ${invalid`, false, 0},
+	{`${if state}unterminated|${endif}`, false, 0},
+	{`${else}`, false, 0},
+	{`${endif}`, false, 0},
+	{`${if a}${endif}${endif}`, false, 0},
 }
 
 func TestPieces(t *testing.T) {
@@ -175,3 +204,260 @@ func TestMatrix(t *testing.T) {
 		}
 	}
 }
+
+var progressbarTests = []struct {
+	param    string
+	width    int
+	status   pms_mpd.PlayerStatus
+	segments []topbar.Segment
+}{
+	{``, 5, pms_mpd.PlayerStatus{}, []topbar.Segment{{``, `progressFilled`}, {`-----`, `progressEmpty`}}},
+	{`ascii`, 6, pms_mpd.PlayerStatus{}, []topbar.Segment{{`[`, `progressFilled`}, {`----]`, `progressEmpty`}}},
+	{`#.`, 3, pms_mpd.PlayerStatus{}, []topbar.Segment{{``, `progressFilled`}, {`...`, `progressEmpty`}}},
+	{`unicode`, 2, pms_mpd.PlayerStatus{}, []topbar.Segment{{``, `progressFilled`}, {`░░`, `progressEmpty`}}},
+	{`ascii`, 2, pms_mpd.PlayerStatus{}, nil},
+	{`全半`, 5, pms_mpd.PlayerStatus{}, []topbar.Segment{{``, `progressFilled`}, {`半半 `, `progressEmpty`}}},
+
+	// Partially filled
+	{``, 10, pms_mpd.PlayerStatus{Time: 100, ElapsedPercentage: 50}, []topbar.Segment{{`=====>`, `progressFilled`}, {`----`, `progressEmpty`}}},
+	{`ascii`, 12, pms_mpd.PlayerStatus{Time: 100, ElapsedPercentage: 25}, []topbar.Segment{{`[==>`, `progressFilled`}, {`-------]`, `progressEmpty`}}},
+	{`#.`, 4, pms_mpd.PlayerStatus{Time: 100, ElapsedPercentage: 50}, []topbar.Segment{{`##`, `progressFilled`}, {`..`, `progressEmpty`}}},
+
+	// Completely filled, without room for the head
+	{``, 10, pms_mpd.PlayerStatus{Time: 100, ElapsedPercentage: 100}, []topbar.Segment{{`==========`, `progressFilled`}, {``, `progressEmpty`}}},
+	{`unicode`, 4, pms_mpd.PlayerStatus{Time: 100, ElapsedPercentage: 100}, []topbar.Segment{{`████`, `progressFilled`}, {``, `progressEmpty`}}},
+	{`ascii`, 7, pms_mpd.PlayerStatus{Time: 100, ElapsedPercentage: 150}, []topbar.Segment{{`[=====`, `progressFilled`}, {`]`, `progressEmpty`}}},
+
+	// Glyphs given literally, including characters with special meaning
+	{`=>-`, 10, pms_mpd.PlayerStatus{Time: 100, ElapsedPercentage: 50}, []topbar.Segment{{`=====>`, `progressFilled`}, {`----`, `progressEmpty`}}},
+	{`[=>-]`, 12, pms_mpd.PlayerStatus{Time: 100, ElapsedPercentage: 25}, []topbar.Segment{{`[==>`, `progressFilled`}, {`-------]`, `progressEmpty`}}},
+	{`█▌░`, 4, pms_mpd.PlayerStatus{Time: 100, ElapsedPercentage: 50}, []topbar.Segment{{`██▌`, `progressFilled`}, {`░`, `progressEmpty`}}},
+	{`|$;`, 4, pms_mpd.PlayerStatus{Time: 100, ElapsedPercentage: 50}, []topbar.Segment{{`||$`, `progressFilled`}, {`;`, `progressEmpty`}}},
+	{`"{ }"`, 4, pms_mpd.PlayerStatus{Time: 100, ElapsedPercentage: 50}, []topbar.Segment{{`{{ `, `progressFilled`}, {`}`, `progressEmpty`}}},
+}
+
+// progressbarFragment parses a progress bar variable with the given
+// parameter, and returns the fragment.
+func progressbarFragment(t *testing.T, a api.API, param string) topbar.Fragment {
+	input := `${progressbar}`
+	if len(param) > 0 {
+		input = `${progressbar|` + param + `}`
+	}
+	matrix, err := topbar.Parse(a, input)
+	if !assert.Nil(t, err, "Expected success when parsing '%s'", input) {
+		return nil
+	}
+	return matrix.Rows[0].Pieces[0].Fragments[0].Instance
+}
+
+// Test that the progress bar fills exactly the requested width.
+func TestProgressbar(t *testing.T) {
+	for n, test := range progressbarTests {
+		t.Logf("### Test %d: '%s' with width %d", n+1, test.param, test.width)
+
+		a := api.NewTestAPIWithState(test.status, nil)
+		frag := progressbarFragment(t, a, test.param)
+		stretchable, ok := frag.(topbar.Stretchable)
+		if assert.True(t, ok) {
+			assert.Equal(t, test.segments, stretchable.Stretch(test.width))
+		}
+	}
+
+	// Invalid glyph specifications are rendered as text.
+	frag := progressbarFragment(t, api.NewTestAPI(), `ab=>c-`)
+	_, ok := frag.(topbar.Stretchable)
+	assert.False(t, ok)
+}
+
+var progressbarClickTests = []struct {
+	param   string
+	offset  int
+	width   int
+	time    int
+	command string
+}{
+	{``, 0, 10, 100, `seek 0`},
+	{``, 5, 10, 100, `seek 50`},
+	{``, 9, 10, 100, `seek 90`},
+	{``, 10, 10, 100, ``},
+	{``, 5, 10, 0, ``},
+
+	// Clicks on the delimiters are ignored.
+	{`ascii`, 0, 12, 200, ``},
+	{`ascii`, 1, 12, 200, `seek 0`},
+	{`ascii`, 6, 12, 200, `seek 100`},
+	{`ascii`, 10, 12, 200, `seek 180`},
+	{`ascii`, 11, 12, 200, ``},
+}
+
+// Test that clicking the progress bar seeks to the clicked position.
+func TestProgressbarClick(t *testing.T) {
+	for n, test := range progressbarClickTests {
+		a := api.NewTestAPIWithState(pms_mpd.PlayerStatus{Time: test.time}, nil)
+		frag := progressbarFragment(t, a, test.param)
+		clickable, ok := frag.(topbar.Clickable)
+		if assert.True(t, ok) {
+			assert.Equal(t, test.command, clickable.Click(test.offset, test.width), "test %d", n+1)
+		}
+	}
+}
+
+// testQueue returns a queue with three songs, with IDs 10, 11 and 12.
+func testQueue(t *testing.T) *songlist.Queue {
+	list := songlist.New()
+	for i, title := range []string{"first", "second", "third"} {
+		s := song.New()
+		s.SetTags(mpd.Attrs{
+			"id":    fmt.Sprintf("%d", i+10),
+			"pos":   fmt.Sprintf("%d", i),
+			"title": title,
+			"time":  "100",
+		})
+		list.Add(s)
+	}
+	queue, err := songlist.NewQueue(func() *mpd.Client { return nil }, nil).Merge(list)
+	assert.Nil(t, err)
+	return queue
+}
+
+var statusFragmentTests = []struct {
+	variable string
+	param    string
+	status   pms_mpd.PlayerStatus
+	text     string
+	style    string
+}{
+	{`audio`, ``, pms_mpd.PlayerStatus{Audio: "44100:16:2"}, `44.1 kHz, 16 bit, stereo`, `audio`},
+	{`audio`, ``, pms_mpd.PlayerStatus{Audio: "96000:f:1"}, `96 kHz, float, mono`, `audio`},
+	{`audio`, ``, pms_mpd.PlayerStatus{Audio: "dsd64:2"}, `dsd64:2`, `audio`},
+	{`audio`, `raw`, pms_mpd.PlayerStatus{Audio: "48000:24:6"}, `48000:24:6`, `audio`},
+	{`bitrate`, ``, pms_mpd.PlayerStatus{Bitrate: 320}, `320 kbps`, `bitrate`},
+	{`bitrate`, ``, pms_mpd.PlayerStatus{}, ``, `bitrate`},
+	{`queue`, `length`, pms_mpd.PlayerStatus{}, `3`, `queueLength`},
+	{`queue`, `duration`, pms_mpd.PlayerStatus{}, `05:00`, `queueDuration`},
+	{`queue`, `remaining`, pms_mpd.PlayerStatus{State: pms_mpd.StatePlay, Song: 1, Elapsed: 40}, `02:40`, `queueDuration`},
+	{`queue`, `remaining`, pms_mpd.PlayerStatus{State: pms_mpd.StateStop, Song: 1, Elapsed: 40}, `05:00`, `queueDuration`},
+	{`next`, `title`, pms_mpd.PlayerStatus{NextSong: 0, NextSongID: 12}, `third`, `title`},
+	{`next`, `title`, pms_mpd.PlayerStatus{NextSong: 1, NextSongID: song.NullID}, `second`, `title`},
+	{`next`, `artist`, pms_mpd.PlayerStatus{NextSong: 1, NextSongID: 11}, `<unknown>`, `tagMissing`},
+	{`next`, `title`, pms_mpd.PlayerStatus{NextSong: song.NullPosition, NextSongID: song.NullID}, `<none>`, `tagMissing`},
+	{`clock`, `%%`, pms_mpd.PlayerStatus{}, `%`, `clock`},
+}
+
+// Test fragments that depend on the player status and the queue.
+func TestStatusFragments(t *testing.T) {
+	for n, test := range statusFragmentTests {
+		t.Logf("### Test %d: ${%s|%s}", n+1, test.variable, test.param)
+
+		a := api.NewTestAPIWithState(test.status, testQueue(t))
+		stmt := &topbar.FragmentStatement{Variable: test.variable, Param: test.param}
+		frag, err := topbar.NewFragment(a, stmt)
+		assert.Nil(t, err)
+
+		text, style := frag.Text()
+		assert.Equal(t, test.text, text)
+		assert.Equal(t, test.style, style)
+	}
+}
+
+var visibleTests = []struct {
+	input  string
+	status pms_mpd.PlayerStatus
+	output string
+}{
+	{`${if state=play}playing${endif}`, pms_mpd.PlayerStatus{State: pms_mpd.StatePlay}, `playing`},
+	{`${if state=play}playing${endif}`, pms_mpd.PlayerStatus{State: pms_mpd.StateStop}, ``},
+	{`${if state=play}playing${else}idle${endif}`, pms_mpd.PlayerStatus{State: pms_mpd.StateStop}, `idle`},
+	{`${if state}[${if state=pause}paused${else}${state}${endif}]${endif}`, pms_mpd.PlayerStatus{State: pms_mpd.StatePause}, `[paused]`},
+	{`${if state}[${if state=pause}paused${else}${state}${endif}]${endif}`, pms_mpd.PlayerStatus{State: pms_mpd.StatePlay}, `[|>]`},
+	{`${if state}[${if state=pause}paused${else}${state}${endif}]${endif}`, pms_mpd.PlayerStatus{}, ``},
+	{`${if tag|artist}by ${tag|artist}${endif}`, pms_mpd.PlayerStatus{}, `by foo`},
+	{`${if tag|album}on ${tag|album}${endif}`, pms_mpd.PlayerStatus{}, ``},
+	{`${tag|albumartist ?: tag|artist}`, pms_mpd.PlayerStatus{}, `foo`},
+	{`${tag|album ?: tag|albumartist}`, pms_mpd.PlayerStatus{}, `<unknown>`},
+	{`${tag|title ?: tag|artist}`, pms_mpd.PlayerStatus{}, `bar`},
+}
+
+// Test that conditionals and fallbacks are evaluated correctly.
+func TestVisible(t *testing.T) {
+	for n, test := range visibleTests {
+		t.Logf("### Test %d: '%s'", n+1, test.input)
+
+		a := api.NewTestAPIWithState(test.status, nil)
+		matrix, err := topbar.Parse(a, test.input)
+		assert.Nil(t, err)
+
+		output := ""
+		for _, frag := range matrix.Rows[0].Pieces[0].Visible() {
+			text, _ := frag.Text()
+			output += text
+		}
+		assert.Equal(t, test.output, output)
+	}
+
+	// Inline styles take precedence over the fragment style.
+	a := api.NewTestAPI()
+	matrix, err := topbar.Parse(a, `${tag|title:style=foo ?: tag|artist}`)
+	assert.Nil(t, err)
+	_, style := matrix.Rows[0].Pieces[0].Visible()[0].Text()
+	assert.Equal(t, `foo`, style)
+}
+
+// Test that statusbar fragments are only available in the statusbar.
+func TestStatusbar(t *testing.T) {
+	a := api.NewTestAPI()
+
+	_, err := topbar.Parse(a, `$selection`)
+	assert.NotNil(t, err)
+
+	matrix, err := topbar.ParseStatusbar(a, `${selection} ${selection|duration}|$volume`)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(matrix.Rows[0].Pieces))
+
+	list := a.Songlist()
+	for i := 0; i < 3; i++ {
+		s := song.New()
+		s.SetTags(mpd.Attrs{"time": "70"})
+		list.Add(s)
+	}
+
+	// No selection
+	visible := matrix.Rows[0].Pieces[0].Visible()
+	text, _ := visible[0].Text()
+	assert.Equal(t, ``, text)
+
+	list.SetSelected(0, true)
+	list.SetSelected(2, true)
+
+	text, style := visible[0].Text()
+	assert.Equal(t, `2 selected`, text)
+	assert.Equal(t, `selectionCount`, style)
+
+	text, style = visible[2].Text()
+	assert.Equal(t, `02:20`, text)
+	assert.Equal(t, `selectionDuration`, style)
+}
+
+var clockTests = []struct {
+	input   string
+	pattern string
+}{
+	{`${clock}`, `^\d{2}:\d{2}$`},
+	{`${clock|%Y-%m-%d}`, `^\d{4}-\d{2}-\d{2}$`},
+	{`${clock|%H:%M:%S:style=time}`, `^\d{2}:\d{2}:\d{2}$`},
+	{`${clock|"%Y-%m-%d %H:%M"}`, `^\d{4}-\d{2}-\d{2} \d{2}:\d{2}$`},
+	{`${clock|[%a]#%j}`, `^\[\w{3}\]#\d{3}$`},
+}
+
+// Test that clock formats are parsed from the topbar syntax, including
+// formats containing characters with special meaning.
+func TestClock(t *testing.T) {
+	for _, test := range clockTests {
+		matrix, err := topbar.Parse(api.NewTestAPI(), test.input)
+		if !assert.Nil(t, err, "Expected success when parsing '%s'", test.input) {
+			continue
+		}
+		text, _ := matrix.Rows[0].Pieces[0].Fragments[0].Instance.Text()
+		assert.Regexp(t, test.pattern, text, test.input)
+	}
+}
@@ -0,0 +1,59 @@
+package utils
+
+import (
+	"strings"
+	"unicode"
+)
+
+// NaturalCompare compares two strings, treating runs of digits as numbers, so
+// that "2" sorts before "10". The result is -1 if a < b, 0 if a == b, and +1
+// if a > b.
+func NaturalCompare(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	i, j := 0, 0
+
+	for i < len(ra) && j < len(rb) {
+		if unicode.IsDigit(ra[i]) && unicode.IsDigit(rb[j]) {
+			// Extract both numbers, and skip leading zeroes.
+			si, sj := i, j
+			for i < len(ra) && unicode.IsDigit(ra[i]) {
+				i++
+			}
+			for j < len(rb) && unicode.IsDigit(rb[j]) {
+				j++
+			}
+			na := strings.TrimLeft(string(ra[si:i]), "0")
+			nb := strings.TrimLeft(string(rb[sj:j]), "0")
+
+			// A longer number is always larger.
+			if len(na) != len(nb) {
+				return compareInt(len(na), len(nb))
+			}
+			if c := strings.Compare(na, nb); c != 0 {
+				return c
+			}
+			continue
+		}
+
+		if ra[i] != rb[j] {
+			return compareInt(int(ra[i]), int(rb[j]))
+		}
+		i++
+		j++
+	}
+
+	return compareInt(len(ra)-i, len(rb)-j)
+}
+
+// compareInt returns -1, 0, or +1 depending on whether a is less than, equal
+// to, or greater than b.
+func compareInt(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,33 @@
+package utils_test
+
+import (
+	"testing"
+
+	"github.com/ambientsound/pms/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+var naturalCompareTests = []struct {
+	a      string
+	b      string
+	result int
+}{
+	{"", "", 0},
+	{"1", "1", 0},
+	{"2", "10", -1},
+	{"10", "2", 1},
+	{"01", "1", 0},
+	{"1/2", "10/12", -1},
+	{"disc 2", "disc 10", -1},
+	{"1999", "2001-05-01", -1},
+	{"2001-05-01", "2001-5-2", -1},
+	{"2001", "2001-05", -1},
+	{"abc", "abd", -1},
+	{"a2b", "a2a", 1},
+}
+
+func TestNaturalCompare(t *testing.T) {
+	for _, test := range naturalCompareTests {
+		assert.Equal(t, test.result, utils.NaturalCompare(test.a, test.b), "'%s' <=> '%s'", test.a, test.b)
+	}
+}
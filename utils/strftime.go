@@ -0,0 +1,80 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// strftimeLayouts maps strftime conversion specifications to Go time layouts.
+var strftimeLayouts = map[rune]string{
+	'a': "Mon",
+	'A': "Monday",
+	'b': "Jan",
+	'B': "January",
+	'd': "02",
+	'e': "_2",
+	'h': "Jan",
+	'H': "15",
+	'I': "03",
+	'm': "01",
+	'M': "04",
+	'p': "PM",
+	'S': "05",
+	'y': "06",
+	'Y': "2006",
+	'z': "-0700",
+	'Z': "MST",
+	'D': "01/02/06",
+	'F': "2006-01-02",
+	'R': "15:04",
+	'T': "15:04:05",
+}
+
+// Strftime formats a time according to a strftime-style format string, e.g.
+// "%Y-%m-%d %H:%M". Unknown conversion specifications are left as-is.
+func Strftime(format string, t time.Time) string {
+	var buf strings.Builder
+	percent := false
+
+	for _, r := range format {
+		if !percent {
+			if r == '%' {
+				percent = true
+			} else {
+				buf.WriteRune(r)
+			}
+			continue
+		}
+
+		percent = false
+
+		switch r {
+		case '%':
+			buf.WriteRune('%')
+		case 'j':
+			buf.WriteString(fmt.Sprintf("%03d", t.YearDay()))
+		case 'k':
+			buf.WriteString(fmt.Sprintf("%2d", t.Hour()))
+		case 's':
+			buf.WriteString(fmt.Sprintf("%d", t.Unix()))
+		case 'u':
+			buf.WriteString(fmt.Sprintf("%d", (int(t.Weekday())+6)%7+1))
+		case 'w':
+			buf.WriteString(fmt.Sprintf("%d", int(t.Weekday())))
+		default:
+			if layout, ok := strftimeLayouts[r]; ok {
+				buf.WriteString(t.Format(layout))
+			} else {
+				buf.WriteRune('%')
+				buf.WriteRune(r)
+			}
+		}
+	}
+
+	if percent {
+		buf.WriteRune('%')
+	}
+
+	return buf.String()
+}
@@ -0,0 +1,31 @@
+package utils_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ambientsound/pms/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+var strftimeTests = []struct {
+	format string
+	output string
+}{
+	{`%H:%M`, `09:05`},
+	{`%Y-%m-%d %H:%M:%S`, `2017-03-04 09:05:07`},
+	{`%a %b %e`, `Sat Mar  4`},
+	{`%I %p`, `09 AM`},
+	{`%j %u %w`, `063 6 6`},
+	{`100%%`, `100%`},
+	{`%Q`, `%Q`},
+	{`trailing %`, `trailing %`},
+}
+
+func TestStrftime(t *testing.T) {
+	tm := time.Date(2017, 3, 4, 9, 5, 7, 0, time.UTC)
+	for n, test := range strftimeTests {
+		t.Logf("### Test %d: '%s'", n+1, test.format)
+		assert.Equal(t, test.output, utils.Strftime(test.format, tm))
+	}
+}
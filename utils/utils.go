@@ -61,3 +61,11 @@ func Max(a, b int) int {
 	}
 	return b
 }
+
+// Abs returns the absolute value of a.
+func Abs(a int) int {
+	if a < 0 {
+		return -a
+	}
+	return a
+}
@@ -0,0 +1,119 @@
+package utils
+
+import (
+	"unicode"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// Ellipsis is drawn in place of the text that is cut off when truncating.
+const Ellipsis = '…'
+
+// zeroWidthJoiner joins the surrounding characters into a single glyph.
+const zeroWidthJoiner = '\u200d'
+
+// Cluster is a single user-perceived character: a base rune, followed by any
+// combining runes. A cluster occupies one terminal cell, or two if the
+// character is wide.
+type Cluster struct {
+	Rune      rune
+	Combining []rune
+	Width     int
+}
+
+// Clusters splits a rune slice into clusters. Zero-width runes, such as
+// combining marks, are attached to the preceding cluster, and so is any rune
+// following a zero-width joiner. Control characters are dropped.
+func Clusters(runes []rune) []Cluster {
+	clusters := make([]Cluster, 0, len(runes))
+	join := false
+	for _, r := range runes {
+		if unicode.IsControl(r) {
+			continue
+		}
+		width := runewidth.RuneWidth(r)
+		n := len(clusters)
+		switch {
+		case n > 0 && (width == 0 || join):
+			clusters[n-1].Combining = append(clusters[n-1].Combining, r)
+		case width == 0:
+			// Combining rune at the start of the text; attach it to a space.
+			clusters = append(clusters, Cluster{Rune: ' ', Combining: []rune{r}, Width: 1})
+		default:
+			clusters = append(clusters, Cluster{Rune: r, Width: width})
+		}
+		join = r == zeroWidthJoiner
+	}
+	return clusters
+}
+
+// ClustersWidth returns the number of terminal cells needed to draw clusters.
+func ClustersWidth(clusters []Cluster) int {
+	width := 0
+	for _, c := range clusters {
+		width += c.Width
+	}
+	return width
+}
+
+// RunesWidth returns the number of terminal cells needed to draw a rune slice.
+func RunesWidth(runes []rune) int {
+	width := 0
+	join := false
+	for _, r := range runes {
+		if !join && !unicode.IsControl(r) {
+			width += runewidth.RuneWidth(r)
+		}
+		join = r == zeroWidthJoiner
+	}
+	return width
+}
+
+// StringWidth returns the number of terminal cells needed to draw a string.
+func StringWidth(s string) int {
+	return RunesWidth([]rune(s))
+}
+
+// Truncate returns as many clusters as will fit within the given width. If
+// the clusters do not fit, the text is cut off and ends with an ellipsis.
+func Truncate(clusters []Cluster, width int) []Cluster {
+	if ClustersWidth(clusters) <= width {
+		return clusters
+	}
+
+	ellipsis := Cluster{Rune: Ellipsis, Width: runewidth.RuneWidth(Ellipsis)}
+	if width < ellipsis.Width {
+		return []Cluster{}
+	}
+
+	truncated := make([]Cluster, 0, width)
+	used := ellipsis.Width
+	for _, c := range clusters {
+		if used+c.Width > width {
+			break
+		}
+		truncated = append(truncated, c)
+		used += c.Width
+	}
+
+	return append(truncated, ellipsis)
+}
+
+// CellIndex returns the index of the rune drawn at the given cell offset, or
+// the length of the rune slice if the offset is beyond the end of the text.
+func CellIndex(runes []rune, offset int) int {
+	width := 0
+	join := false
+	for i, r := range runes {
+		w := 0
+		if !join && !unicode.IsControl(r) {
+			w = runewidth.RuneWidth(r)
+		}
+		join = r == zeroWidthJoiner
+		if w > 0 && width+w > offset {
+			return i
+		}
+		width += w
+	}
+	return len(runes)
+}
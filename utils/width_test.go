@@ -0,0 +1,92 @@
+package utils_test
+
+import (
+	"testing"
+
+	"github.com/ambientsound/pms/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+var runesWidthTests = []struct {
+	input string
+	width int
+}{
+	{"", 0},
+	{"abc", 3},
+	{"日本語", 6},
+	{"e\u0301te\u0301", 3},
+	{"한국어 text", 11},
+	{"tab\there", 7},
+}
+
+func TestRunesWidth(t *testing.T) {
+	for _, test := range runesWidthTests {
+		assert.Equal(t, test.width, utils.RunesWidth([]rune(test.input)), test.input)
+		assert.Equal(t, test.width, utils.ClustersWidth(utils.Clusters([]rune(test.input))), test.input)
+	}
+}
+
+// Test that combining characters are attached to the preceding character.
+func TestClusters(t *testing.T) {
+	clusters := utils.Clusters([]rune("e\u0301日"))
+	assert.Equal(t, []utils.Cluster{
+		{Rune: 'e', Combining: []rune{'\u0301'}, Width: 1},
+		{Rune: '日', Width: 2},
+	}, clusters)
+
+	clusters = utils.Clusters([]rune("\u0301a"))
+	assert.Equal(t, []utils.Cluster{
+		{Rune: ' ', Combining: []rune{'\u0301'}, Width: 1},
+		{Rune: 'a', Width: 1},
+	}, clusters)
+}
+
+var truncateTests = []struct {
+	input  string
+	width  int
+	output string
+}{
+	{"abcdef", 6, "abcdef"},
+	{"abcdef", 10, "abcdef"},
+	{"abcdef", 4, "abc…"},
+	{"abcdef", 1, "…"},
+	{"abcdef", 0, ""},
+	{"日本語", 6, "日本語"},
+	{"日本語", 5, "日本…"},
+	{"日本語", 4, "日…"},
+	{"e\u0301e\u0301e\u0301", 2, "e\u0301…"},
+}
+
+func TestTruncate(t *testing.T) {
+	for _, test := range truncateTests {
+		clusters := utils.Truncate(utils.Clusters([]rune(test.input)), test.width)
+		runes := make([]rune, 0)
+		for _, c := range clusters {
+			runes = append(runes, c.Rune)
+			runes = append(runes, c.Combining...)
+		}
+		assert.Equal(t, test.output, string(runes), "%s truncated to %d", test.input, test.width)
+		assert.True(t, utils.ClustersWidth(clusters) <= test.width)
+	}
+}
+
+var cellIndexTests = []struct {
+	input  string
+	offset int
+	index  int
+}{
+	{"abc", 0, 0},
+	{"abc", 2, 2},
+	{"abc", 5, 3},
+	{"日本語", 0, 0},
+	{"日本語", 1, 0},
+	{"日本語", 2, 1},
+	{"日本語", 5, 2},
+	{"e\u0301x", 1, 2},
+}
+
+func TestCellIndex(t *testing.T) {
+	for _, test := range cellIndexTests {
+		assert.Equal(t, test.index, utils.CellIndex([]rune(test.input), test.offset), "%s at %d", test.input, test.offset)
+	}
+}
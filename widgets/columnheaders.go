@@ -5,6 +5,7 @@ import (
 
 	"github.com/ambientsound/pms/songlist"
 	"github.com/ambientsound/pms/style"
+	"github.com/ambientsound/pms/utils"
 	"github.com/gdamore/tcell"
 	"github.com/gdamore/tcell/views"
 )
@@ -33,15 +34,36 @@ func (c *ColumnheadersWidget) Draw() {
 	for i := range c.columns {
 		col := c.columns[i]
 		title := []rune(strings.Title(col.Tag()))
+
+		// The last column has no right padding.
+		width := col.Width() - 1
+		if i+1 == len(c.columns) {
+			width = col.Width()
+		}
+
 		p := 0
-		for _, r := range title {
-			c.view.SetContent(x+p, y, r, nil, c.Style("header"))
-			p++
+		if col.RightAligned() {
+			p = utils.Max(0, width-utils.RunesWidth(title))
 		}
+		drawText(c.view, x+p, y, width-p, title, c.Style("header"))
 		x += col.Width()
 	}
 }
 
+// ColumnAt returns the column drawn at the given x position, or nil if there
+// is no column there.
+func (c *ColumnheadersWidget) ColumnAt(x int) *songlist.Column {
+	start := 0
+	for i := range c.columns {
+		end := start + c.columns[i].Width()
+		if x >= start && x < end {
+			return c.columns[i]
+		}
+		start = end
+	}
+	return nil
+}
+
 func (c *ColumnheadersWidget) SetView(v views.View) {
 	c.view = v
 }
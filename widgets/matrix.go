@@ -0,0 +1,163 @@
+package widgets
+
+import (
+	"github.com/ambientsound/pms/topbar"
+	"github.com/ambientsound/pms/utils"
+	"github.com/gdamore/tcell"
+	"github.com/gdamore/tcell/views"
+)
+
+// Pieces may be aligned to left, center or right.
+const (
+	AlignLeft = iota
+	AlignCenter
+	AlignRight
+)
+
+// matrix draws a parsed topbar matrix statement, and keeps track of where
+// each fragment was drawn. It is shared between the topbar and the statusbar.
+type matrix struct {
+	stmt  *topbar.MatrixStatement
+	areas []fragmentArea
+	view  views.View
+	style func(string) tcell.Style
+}
+
+// fragmentArea is the screen area that a fragment was drawn to.
+type fragmentArea struct {
+	x, y, width int
+	fragment    topbar.Fragment
+}
+
+// draw draws all the pieces in the matrix, from top to bottom, right to left,
+// on top of a background style.
+func (m *matrix) draw(v views.View, style func(string) tcell.Style, background tcell.Style) {
+	m.view = v
+	m.style = style
+	m.areas = m.areas[:0]
+
+	xmax, _ := v.Size()
+
+	// Blank screen first
+	v.Fill(' ', background)
+
+	for y, rowStmt := range m.stmt.Rows {
+		// Calculate window buffer width
+		pieces := len(rowStmt.Pieces)
+		if pieces == 0 {
+			continue
+		}
+
+		for piece, pieceStmt := range rowStmt.Pieces {
+			x := getPiecesStartX(piece, pieces, xmax)
+			x2 := getPiecesStartX(piece+1, pieces, xmax)
+			m.drawPiece(x, x2, y, piece, pieces, pieceStmt)
+		}
+	}
+}
+
+// drawPiece draws all fragments in a piece between x and x2. Any space left
+// over is divided between stretchable fragments; if there are none, the
+// fragments are aligned left, center or right within the available space.
+func (m *matrix) drawPiece(x, x2, y, piece, pieces int, pieceStmt *topbar.PieceStatement) {
+	visible := pieceStmt.Visible()
+	texts := make([]string, len(visible))
+	styles := make([]string, len(visible))
+	textWidth := 0
+	stretchables := 0
+
+	for i, fragmentStmt := range visible {
+		if _, ok := fragmentStmt.Instance.(topbar.Stretchable); ok {
+			stretchables++
+			continue
+		}
+		texts[i], styles[i] = fragmentStmt.Text()
+		textWidth += utils.StringWidth(texts[i])
+	}
+
+	// Reset X position to start of window buffer, and align left,
+	// center or right.
+	// Text that is too wide for the piece is truncated at the right edge.
+	space := utils.Max(0, x2-x-textWidth)
+	if stretchables == 0 {
+		x = utils.Max(x, alignX(x, x2-x, textWidth, autoAlign(piece, pieces)))
+	}
+
+	stretched := 0
+	for i, fragmentStmt := range visible {
+		frag := fragmentStmt.Instance
+		start := x
+
+		if _, ok := frag.(topbar.Stretchable); ok {
+			// Extra space is assigned to the first stretchable fragments.
+			width := space / stretchables
+			if stretched < space%stretchables {
+				width++
+			}
+			stretched++
+			for _, segment := range fragmentStmt.Stretch(width) {
+				x = m.drawNext(x, x2, y, segment.Text, m.style(segment.Style))
+			}
+		} else {
+			x = m.drawNext(x, x2, y, texts[i], m.style(styles[i]))
+		}
+
+		m.areas = append(m.areas, fragmentArea{start, y, x - start, frag})
+	}
+}
+
+// drawNext draws a string, truncated at x2, and returns the resulting X
+// position.
+func (m *matrix) drawNext(x, x2, y int, s string, style tcell.Style) int {
+	return drawText(m.view, x, y, x2-x, []rune(s), style)
+}
+
+// fragmentAt returns the fragment drawn at the given coordinates, along with
+// the offset into the fragment text and the total width of the text.
+func (m *matrix) fragmentAt(x, y int) (topbar.Fragment, int, int) {
+	for _, area := range m.areas {
+		if y == area.y && x >= area.x && x < area.x+area.width {
+			return area.fragment, x - area.x, area.width
+		}
+	}
+	return nil, 0, 0
+}
+
+// autoAlign returns a best-guess align for a Piece: the outermost indices are
+// left- and right adjusted, while the rest are centered.
+func autoAlign(index, total int) int {
+	switch index {
+	case 0:
+		return AlignLeft
+	case total - 1:
+		return AlignRight
+	default:
+		return AlignCenter
+	}
+}
+
+// getPiecesStartX calculates the start x-position for a given piece.
+//
+// Unused space is avoided by assigning extra space to the first pieces,
+// if (xmax / pieces) leaves a remainder.
+func getPiecesStartX(piece, pieces, xmax int) int {
+	x := piece * (xmax / pieces)
+	if piece <= (xmax % pieces) {
+		return x + piece
+	}
+	return x + (xmax % pieces)
+}
+
+// alignX returns the draw start position.
+func alignX(x, bufferWidth, textWidth, align int) int {
+	switch align {
+	case AlignLeft:
+		return x
+	case AlignCenter:
+		return x + (bufferWidth / 2) - (textWidth / 2)
+	case AlignRight:
+		return x + bufferWidth - textWidth
+	default:
+		return x
+	}
+}
@@ -0,0 +1,160 @@
+package widgets
+
+import (
+	"time"
+
+	"github.com/ambientsound/pms/console"
+	"github.com/ambientsound/pms/constants"
+	"github.com/ambientsound/pms/songlist"
+	"github.com/ambientsound/pms/topbar"
+	"github.com/ambientsound/pms/utils"
+	"github.com/gdamore/tcell"
+	"github.com/gdamore/tcell/views"
+)
+
+// Clicks on the same row within this interval are treated as a double-click.
+const doubleClickInterval = 400 * time.Millisecond
+
+// Number of rows scrolled for every step of the mouse wheel.
+const wheelScrollRows = 3
+
+// mouseState holds state needed to interpret mouse events.
+type mouseState struct {
+	buttons   tcell.ButtonMask
+	lastClick time.Time
+	lastRow   int
+}
+
+// physicalPosition translates screen coordinates into coordinates local to a
+// widget's view. The last return value is false if the coordinates are
+// outside of the view.
+func physicalPosition(v views.View, x, y int) (int, int, bool) {
+	viewport, ok := v.(*views.ViewPort)
+	if !ok {
+		return 0, 0, false
+	}
+	x1, y1, x2, y2 := viewport.GetPhysical()
+	if x < x1 || x > x2 || y < y1 || y > y2 {
+		return 0, 0, false
+	}
+	return x - x1, y - y1, true
+}
+
+// handleMouse dispatches mouse events to the widget under the mouse pointer.
+func (ui *UI) handleMouse(ev *tcell.EventMouse) bool {
+	x, y := ev.Position()
+	buttons := ev.Buttons()
+
+	// Only react to buttons that were pressed since the last event; tcell
+	// sends repeated events while the mouse is dragged.
+	pressed := buttons &^ ui.mouse.buttons
+	ui.mouse.buttons = buttons
+
+	switch {
+	case buttons&tcell.WheelUp != 0:
+		return ui.handleMouseWheel(x, y, -wheelScrollRows)
+	case buttons&tcell.WheelDown != 0:
+		return ui.handleMouseWheel(x, y, wheelScrollRows)
+	case pressed&tcell.Button1 == 0:
+		return false
+	}
+
+	shift := ev.Modifiers()&tcell.ModShift != 0
+
+	if lx, ly, ok := physicalPosition(ui.Songlist.view, x, y); ok {
+		return ui.handleSonglistClick(lx, ly, shift)
+	}
+	if lx, _, ok := physicalPosition(ui.Columnheaders.view, x, y); ok {
+		return ui.handleColumnheadersClick(lx)
+	}
+	if lx, ly, ok := physicalPosition(ui.Topbar.view, x, y); ok {
+		return ui.handleTopbarClick(lx, ly)
+	}
+
+	// The multibar always occupies the bottom line of the screen.
+	if _, ymax := ui.Screen.Size(); y == ymax-1 {
+		return ui.Multibar.HandleClick(x)
+	}
+
+	return false
+}
+
+// handleMouseWheel scrolls the songlist viewport.
+func (ui *UI) handleMouseWheel(x, y, delta int) bool {
+	if _, _, ok := physicalPosition(ui.Songlist.view, x, y); !ok {
+		return false
+	}
+	ui.Songlist.ScrollViewport(delta, false)
+	return true
+}
+
+// handleSonglistClick moves the cursor to the clicked song. With the shift
+// modifier, the visual selection is extended to the clicked song. Clicking
+// twice on the same song starts playing it, or opens the entry in views such
+// as the lists view.
+func (ui *UI) handleSonglistClick(x, y int, shift bool) bool {
+	list := ui.Songlist.List()
+	row := ui.Songlist.RowAt(y)
+	if !list.InRange(row) {
+		return false
+	}
+
+	now := time.Now()
+	double := row == ui.mouse.lastRow && now.Sub(ui.mouse.lastClick) < doubleClickInterval
+	ui.mouse.lastClick = now
+	ui.mouse.lastRow = row
+
+	if shift && !list.HasVisualSelection() {
+		list.EnableVisualSelection()
+	}
+	list.SetCursor(row)
+
+	if double && !shift {
+		ui.mouse.lastClick = time.Time{}
+		if _, ok := list.(songlist.Opener); ok {
+			ui.EventInputCommand <- "list open"
+		} else {
+			ui.EventInputCommand <- "play cursor"
+		}
+	}
+
+	return true
+}
+
+// handleColumnheadersClick sorts the songlist by the clicked column.
+func (ui *UI) handleColumnheadersClick(x int) bool {
+	col := ui.Columnheaders.ColumnAt(x)
+	if col == nil {
+		return false
+	}
+	console.Log("Column header '%s' clicked, sorting list", col.Tag())
+	ui.EventInputCommand <- "sort " + col.Tag()
+	return true
+}
+
+// handleTopbarClick runs the command associated with the clicked topbar fragment.
+func (ui *UI) handleTopbarClick(x, y int) bool {
+	frag, offset, width := ui.Topbar.FragmentAt(x, y)
+	clickable, ok := frag.(topbar.Clickable)
+	if !ok {
+		return false
+	}
+	cmd := clickable.Click(offset, width)
+	if len(cmd) == 0 {
+		return false
+	}
+	ui.EventInputCommand <- cmd
+	return true
+}
+
+// HandleClick moves the text cursor to the clicked position while in one of
+// the text input modes.
+func (m *MultibarWidget) HandleClick(x int) bool {
+	switch m.inputMode {
+	case constants.MultibarModeInput, constants.MultibarModeSearch:
+		// Account for the ':' or '/' prefix.
+		m.handleCursor(utils.CellIndex(m.runes, x-1) - m.cursor)
+		return true
+	}
+	return false
+}
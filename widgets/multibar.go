@@ -13,6 +13,7 @@ import (
 	"github.com/ambientsound/pms/message"
 	"github.com/ambientsound/pms/style"
 	"github.com/ambientsound/pms/tabcomplete"
+	"github.com/ambientsound/pms/topbar"
 	"github.com/ambientsound/pms/utils"
 
 	"github.com/gdamore/tcell"
@@ -26,7 +27,8 @@ type history struct {
 	index   int
 }
 
-// MultibarWidget receives keyboard events, displays status messages, and the position readout.
+// MultibarWidget receives keyboard events, and displays either the text input
+// or the statusbar.
 type MultibarWidget struct {
 	api         api.API
 	cursor      int
@@ -34,8 +36,9 @@ type MultibarWidget struct {
 	inputMode   int
 	msg         message.Message
 	runes       []rune
+	statusbar   matrix
 	tabComplete *tabcomplete.TabComplete
-	textStyle   tcell.Style
+	view        views.View
 
 	// Three histories, one for each input mode
 	history [3]history
@@ -91,9 +94,10 @@ func (h *history) validateIndex() {
 
 func NewMultibarWidget(a api.API, events chan *tcell.EventKey) *MultibarWidget {
 	return &MultibarWidget{
-		api:    a,
-		runes:  make([]rune, 0),
-		events: events,
+		api:       a,
+		runes:     make([]rune, 0),
+		events:    events,
+		statusbar: matrix{stmt: &topbar.MatrixStatement{}},
 		history: [3]history{
 			{items: make([]string, 0)},
 			{items: make([]string, 0)},
@@ -107,19 +111,29 @@ func (m *MultibarWidget) History() *history {
 	return &m.history[m.inputMode]
 }
 
+// SetMessage sets the message shown in the statusbar. Key sequence
+// messages clear the current message, as the pending key sequence is
+// available through its own statusbar fragment.
 func (m *MultibarWidget) SetMessage(msg message.Message) {
 	switch {
 	case msg.Type == message.SequenceText:
-		m.textStyle = m.Style("sequenceText")
+		msg = message.Message{}
 	case msg.Severity == message.Info:
-		m.textStyle = m.Style("statusbar")
 	case msg.Severity == message.Error:
-		m.textStyle = m.Style("errorText")
 	default:
 		return
 	}
 	m.msg = msg
-	m.DrawStatusbar()
+}
+
+// Message returns the message shown in the statusbar.
+func (m *MultibarWidget) Message() message.Message {
+	return m.msg
+}
+
+// SetStatusbar sets up the statusbar contents using a parsed matrix statement.
+func (m *MultibarWidget) SetStatusbar(stmt *topbar.MatrixStatement) {
+	m.statusbar.stmt = stmt
 }
 
 func (m *MultibarWidget) SetMode(mode int) error {
@@ -148,29 +162,31 @@ func (m *MultibarWidget) setRunes(r []rune) {
 	m.DrawStatusbar()
 }
 
-// Draw the statusbar part of the Multibar.
+// DrawStatusbar updates the text input part of the Multibar.
 func (m *MultibarWidget) DrawStatusbar() {
-	var st tcell.Style
-	var s string
-
 	switch m.inputMode {
 	case constants.MultibarModeInput:
-		s = ":" + m.RuneString()
-		st = m.Style("commandText")
+		m.SetLeft(":"+m.RuneString(), m.Style("commandText"))
 	case constants.MultibarModeSearch:
-		s = "/" + m.RuneString()
-		st = m.Style("searchText")
+		m.SetLeft("/"+m.RuneString(), m.Style("searchText"))
 	default:
-		if len(m.msg.Text) == 0 && m.api.Songlist().HasVisualSelection() {
-			s = "-- VISUAL --"
-			st = m.Style("visualText")
-		} else {
-			s = m.msg.Text
-			st = m.textStyle
-		}
+		m.SetLeft("", m.Style("statusbar"))
+	}
+}
+
+// Draw draws the text input while in one of the input modes, and the
+// statusbar otherwise.
+func (m *MultibarWidget) Draw() {
+	if m.inputMode != constants.MultibarModeNormal {
+		m.TextBar.Draw()
+		return
 	}
+	m.statusbar.draw(m.view, m.Style, m.Style("statusbar"))
+}
 
-	m.SetLeft(s, st)
+func (m *MultibarWidget) SetView(v views.View) {
+	m.view = v
+	m.TextBar.SetView(v)
 }
 
 func (m *MultibarWidget) RuneString() string {
@@ -186,6 +202,12 @@ func (m *MultibarWidget) Cursor() int {
 	return m.cursor
 }
 
+// CursorX returns the screen column of the cursor, relative to the start of
+// the text. Wide characters occupy two columns.
+func (m *MultibarWidget) CursorX() int {
+	return utils.RunesWidth(m.runes[:m.cursor])
+}
+
 // validateCursor makes sure the cursor stays within boundaries.
 func (m *MultibarWidget) validateCursor() {
 	if m.cursor > len(m.runes) {
@@ -1,8 +1,6 @@
 package widgets
 
 import (
-	"fmt"
-	"math"
 	"time"
 
 	"github.com/ambientsound/pms/api"
@@ -36,20 +34,26 @@ func NewSonglistWidget(a api.API) (w *SonglistWidget) {
 	}
 }
 
+// drawNext draws runes left aligned, truncated to strmin cells, and pads the
+// rest of the column up to strmax cells.
 func (w *SonglistWidget) drawNext(x, y, strmin, strmax int, runes []rune, style tcell.Style) int {
-	strmin = utils.Min(len(runes), strmin)
-	n := 0
-	for n < strmin {
-		w.viewport.SetContent(x, y, runes[n], nil, style)
-		n++
-		x++
-	}
-	for n < strmax {
-		w.viewport.SetContent(x, y, ' ', nil, style)
-		n++
-		x++
+	end := x + strmax
+	x = drawText(&w.viewport, x, y, strmin, runes, style)
+	return fill(&w.viewport, x, end, y, style)
+}
+
+// drawNextRight draws runes right aligned within strmin cells, and pads the
+// rest of the column up to strmax cells. Text that does not fit is drawn left
+// aligned and truncated.
+func (w *SonglistWidget) drawNextRight(x, y, strmin, strmax int, runes []rune, style tcell.Style) int {
+	pad := strmin - utils.RunesWidth(runes)
+	if pad < 0 {
+		return w.drawNext(x, y, strmin, strmax, runes, style)
 	}
-	return x
+	end := x + strmax
+	x = fill(&w.viewport, x, x+pad, y, style)
+	x = drawText(&w.viewport, x, y, strmin-pad, runes, style)
+	return fill(&w.viewport, x, end, y, style)
 }
 
 func (w *SonglistWidget) drawOneTagLine(x, y, xmax int, s *song.Song, tag string, defaultStyle string, style tcell.Style, lineStyled bool) int {
@@ -57,10 +61,7 @@ func (w *SonglistWidget) drawOneTagLine(x, y, xmax int, s *song.Song, tag string
 		style = w.Style(defaultStyle)
 	}
 
-	runes := s.Tags[tag]
-	strmin := len(runes)
-
-	return w.drawNext(x, y, strmin, xmax+1, runes, style)
+	return w.drawNext(x, y, xmax-x, xmax-x, s.Tags[tag], style)
 }
 
 func (w *SonglistWidget) Panel() *songlist.Collection {
@@ -102,6 +103,9 @@ func (w *SonglistWidget) Draw() {
 	style := w.Style("default")
 	cursor := false
 
+	// Lists that do not contain music are always drawn column by column.
+	_, columnar := list.(songlist.Columnar)
+
 	for y := ymin; y <= ymax; y++ {
 
 		lineStyled := true
@@ -121,6 +125,8 @@ func (w *SonglistWidget) Draw() {
 			style = w.Style("currentSong")
 		case list.Selected(y):
 			style = w.Style("selection")
+		case s.Prio > 0:
+			style = w.Style("prioritySong")
 		default:
 			style = w.Style("default")
 			lineStyled = false
@@ -130,14 +136,14 @@ func (w *SonglistWidget) Draw() {
 		rightPadding := 1
 
 		// If all essential tags are missing, draw only the filename
-		if !s.HasOneOfTags("artist", "album", "title") {
-			w.drawOneTagLine(x, y, xmax+1, s, `file`, `allTagsMissing`, style, lineStyled)
+		if !columnar && !s.HasOneOfTags("artist", "album", "title") {
+			w.drawOneTagLine(x, y, xmax, s, `file`, `allTagsMissing`, style, lineStyled)
 			continue
 		}
 
 		// If most essential tags are missing, but the title is present, draw only the title.
-		if !s.HasOneOfTags("artist", "album") {
-			w.drawOneTagLine(x, y, xmax+1, s, `title`, `mostTagsMissing`, style, lineStyled)
+		if !columnar && !s.HasOneOfTags("artist", "album") {
+			w.drawOneTagLine(x, y, xmax, s, `title`, `mostTagsMissing`, style, lineStyled)
 			continue
 		}
 
@@ -158,7 +164,11 @@ func (w *SonglistWidget) Draw() {
 			strmax := w.columns[col].Width()
 			strmin := strmax - rightPadding
 
-			x = w.drawNext(x, y, strmin, strmax, runes, style)
+			if w.columns[col].RightAligned() {
+				x = w.drawNextRight(x, y, strmin, strmax, runes, style)
+			} else {
+				x = w.drawNext(x, y, strmin, strmax, runes, style)
+			}
 		}
 	}
 
@@ -171,6 +181,13 @@ func (w *SonglistWidget) GetVisibleBoundaries() (ymin, ymax int) {
 	return
 }
 
+// RowAt returns the list index of the song drawn at the given y position,
+// relative to the top of the widget.
+func (w *SonglistWidget) RowAt(y int) int {
+	_, ymin, _, _ := w.viewport.GetVisible()
+	return ymin + y
+}
+
 // Width returns the widget width.
 func (w *SonglistWidget) Width() int {
 	_, _, xmax, _ := w.viewport.GetVisible()
@@ -230,44 +247,12 @@ func (w *SonglistWidget) Name() string {
 	return w.List().Name()
 }
 
-// PositionReadout returns a combination of PositionLongReadout() and PositionShortReadout().
-// FIXME: move this into a positionreadout fragment
-func (w *SonglistWidget) PositionReadout() string {
-	return fmt.Sprintf("%s    %s", w.PositionLongReadout(), w.PositionShortReadout())
-}
-
-// PositionLongReadout returns a formatted string containing the visible song
-// range as well as the total number of songs.
-// FIXME: move this into a positionreadout fragment
-func (w *SonglistWidget) PositionLongReadout() string {
-	ymin, ymax := w.GetVisibleBoundaries()
-	return fmt.Sprintf("%d,%d-%d/%d", w.List().Cursor()+1, ymin+1, ymax+1, w.List().Len())
-}
-
-// PositionShortReadout returns a percentage indicator on how far the songlist is scrolled.
-// FIXME: move this into a positionreadout fragment
-func (w *SonglistWidget) PositionShortReadout() string {
-	ymin, ymax := w.GetVisibleBoundaries()
-	if ymin == 0 && ymax+1 == w.List().Len() {
-		return `All`
-	}
-	if ymin == 0 {
-		return `Top`
-	}
-	if ymax+1 == w.List().Len() {
-		return `Bot`
-	}
-	fraction := float64(float64(ymin) / float64(w.List().Len()))
-	percent := int(math.Floor(fraction * 100))
-	return fmt.Sprintf("%2d%%", percent)
-}
-
-// SetColumns sets which columns that should be visible
-func (w *SonglistWidget) SetColumns(tags []string) {
+// SetColumns sets which columns that should be visible, and calculates their
+// widths.
+func (w *SonglistWidget) SetColumns(cols songlist.Columns) {
 	xmax, _ := w.Size()
-	w.columns = w.List().Columns(tags)
+	w.columns = cols
 	w.columns.Expand(xmax)
-	//console.Log("SetColumns(%v) yields %+v", tags, w.columns)
 }
 
 // ScrollViewport scrolls the viewport by delta rows, as far as possible.
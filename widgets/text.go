@@ -0,0 +1,27 @@
+package widgets
+
+import (
+	"github.com/ambientsound/pms/utils"
+	"github.com/gdamore/tcell"
+	"github.com/gdamore/tcell/views"
+)
+
+// drawText draws runes starting at x, truncated to fit within width cells,
+// and returns the resulting X position. Wide characters occupy two cells, and
+// combining characters are drawn together with the preceding character.
+func drawText(v views.View, x, y, width int, runes []rune, style tcell.Style) int {
+	clusters := utils.Truncate(utils.Clusters(runes), width)
+	for _, c := range clusters {
+		v.SetContent(x, y, c.Rune, c.Combining, style)
+		x += c.Width
+	}
+	return x
+}
+
+// fill draws spaces from x up to, but not including, x2.
+func fill(v views.View, x, x2, y int, style tcell.Style) int {
+	for ; x < x2; x++ {
+		v.SetContent(x, y, ' ', nil, style)
+	}
+	return x
+}
@@ -2,7 +2,6 @@ package widgets
 
 import (
 	"fmt"
-	"strings"
 
 	"github.com/ambientsound/pms/api"
 	"github.com/ambientsound/pms/console"
@@ -34,6 +33,7 @@ type UI struct {
 	api          api.API
 	options      *options.Options // FIXME: use api instead
 	searchResult songlist.Songlist
+	mouse        mouseState
 
 	// TCell
 	view views.View
@@ -109,6 +109,17 @@ func (ui *UI) Quit() {
 	ui.App.Quit()
 }
 
+// SetMouse enables or disables mouse events.
+func (ui *UI) SetMouse(enabled bool) {
+	ui.App.PostFunc(func() {
+		if enabled {
+			ui.Screen.EnableMouse()
+		} else {
+			ui.Screen.DisableMouse()
+		}
+	})
+}
+
 func (ui *UI) Draw() {
 	ui.Layout.Draw()
 }
@@ -134,12 +145,36 @@ func (ui *UI) UpdateCursor() {
 	switch ui.Multibar.Mode() {
 	case constants.MultibarModeInput, constants.MultibarModeSearch:
 		_, ymax := ui.Screen.Size()
-		ui.Screen.ShowCursor(ui.Multibar.Cursor()+1, ymax-1)
+		ui.Screen.ShowCursor(ui.Multibar.CursorX()+1, ymax-1)
 	default:
 		ui.Screen.HideCursor()
 	}
 }
 
+// ListChanged makes the UI recalculate the tracklist columns.
+func (ui *UI) ListChanged() {
+	PostEventListChanged(ui.Songlist)
+}
+
+// refreshColumns sets up the tracklist columns according to the column
+// options, and calculates their widths.
+func (ui *UI) refreshColumns() {
+	list := ui.api.Songlist()
+	specs, err := songlist.ParseColumnSpecs(songlist.ColumnOption(list, ui.options))
+	if err != nil {
+		ui.api.Error("%s", err)
+		return
+	}
+
+	cols := list.Columns(songlist.Tags(specs))
+	for i := range cols {
+		cols[i].SetSpec(specs[i])
+	}
+
+	ui.Songlist.SetColumns(cols)
+	ui.Columnheaders.SetColumns(cols)
+}
+
 func (ui *UI) PostFunc(f func()) {
 	ui.App.PostFunc(f)
 }
@@ -149,10 +184,7 @@ func (ui *UI) HandleEvent(ev tcell.Event) bool {
 
 	// If a list was changed, make sure we obtain the correct column widths.
 	case *EventListChanged:
-		tags := strings.Split(ui.options.StringValue("columns"), ",")
-		cols := ui.api.Songlist().Columns(tags)
-		ui.Songlist.SetColumns(tags)
-		ui.Columnheaders.SetColumns(cols)
+		ui.refreshColumns()
 		return true
 
 	case *EventInputChanged:
@@ -187,8 +219,10 @@ func (ui *UI) HandleEvent(ev tcell.Event) bool {
 		return true
 
 	case *EventScroll:
-		ui.refreshPositionReadout()
 		return true
+
+	case *tcell.EventMouse:
+		return ui.handleMouse(ev.(*tcell.EventMouse))
 	}
 
 	if ui.Layout.HandleEvent(ev) {
@@ -198,11 +232,6 @@ func (ui *UI) HandleEvent(ev tcell.Event) bool {
 	return false
 }
 
-func (ui *UI) refreshPositionReadout() {
-	str := ui.Songlist.PositionReadout()
-	ui.Multibar.SetRight(str, ui.Style("readout"))
-}
-
 func (ui *UI) runIndexSearch(term string) error {
 	var err error
 
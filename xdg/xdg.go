@@ -5,6 +5,7 @@
 package xdg
 
 import (
+	"fmt"
 	"os"
 	"path"
 	"strings"
@@ -65,3 +66,32 @@ func CacheDirectory() string {
 
 	return path.Join(xdgCacheHome, "pms")
 }
+
+// RuntimeDirectory returns the runtime base directory, where sockets and other
+// non-essential runtime files should be stored.
+func RuntimeDirectory() string {
+	// $XDG_RUNTIME_DIR defines the base directory relative to which user
+	// specific non-essential runtime files and other file objects (such as
+	// sockets, named pipes, ...) should be stored. If $XDG_RUNTIME_DIR is not
+	// set, applications should fall back to a replacement directory with
+	// similar capabilities.
+	xdgRuntimeDir := os.Getenv("XDG_RUNTIME_DIR")
+	if len(xdgRuntimeDir) == 0 {
+		return path.Join(os.TempDir(), fmt.Sprintf("pms-%d", os.Getuid()))
+	}
+
+	return appendPmsDirectory(xdgRuntimeDir)
+}
+
+// DataDirectory returns the data base directory.
+func DataDirectory() string {
+	// $XDG_DATA_HOME defines the base directory relative to which user
+	// specific data files should be stored. If $XDG_DATA_HOME is either not
+	// set or empty, a default equal to $HOME/.local/share should be used.
+	xdgDataHome := os.Getenv("XDG_DATA_HOME")
+	if len(xdgDataHome) == 0 {
+		xdgDataHome = path.Join(os.Getenv("HOME"), ".local", "share")
+	}
+
+	return appendPmsDirectory(xdgDataHome)
+}